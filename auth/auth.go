@@ -0,0 +1,219 @@
+//Package auth authenticates callers with JWT bearer tokens or TLS client certificates
+//and keeps their identity in the context
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+//AdminRole grants access to every user's todos
+const AdminRole = "admin"
+
+//Identity of an authenticated caller
+type Identity struct {
+	//Subject is the token subject or the certificate common name
+	Subject string
+	//UserID is the todo user of the caller, 0 when the subject isn't a user id
+	UserID int32
+	Roles  []string
+}
+
+//IsAdmin reports whether the caller has the admin role
+func (this *Identity) IsAdmin() bool {
+	for _, role := range this.Roles {
+		if role == AdminRole {
+			return true
+		}
+	}
+	return false
+}
+
+type identityKey struct{}
+
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+//FromContext returns the identity of the caller, false when the call isn't authenticated
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+//Claims of the accepted tokens, sub holds the user id
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+//Verifier validates tokens signed with an HMAC key, an RSA key or both
+type Verifier struct {
+	hmacKey []byte
+	rsaKey  *rsa.PublicKey
+}
+
+//LoadVerifier reads the HMAC secret and the PEM encoded RSA public key, empty paths are skipped
+func LoadVerifier(hmacKeyFile string, rsaPublicKeyFile string) (*Verifier, error) {
+	verifier := &Verifier{}
+	if hmacKeyFile != "" {
+		key, err := os.ReadFile(hmacKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.hmacKey = []byte(strings.TrimSpace(string(key)))
+		if len(verifier.hmacKey) == 0 {
+			return nil, fmt.Errorf("hmac key file %s is empty", hmacKeyFile)
+		}
+	}
+	if rsaPublicKeyFile != "" {
+		pem, err := os.ReadFile(rsaPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("rsa public key file %s: %v", rsaPublicKeyFile, err)
+		}
+	}
+	if verifier.hmacKey == nil && verifier.rsaKey == nil {
+		return nil, errors.New("no hmac or rsa key given")
+	}
+	return verifier, nil
+}
+
+func (this *Verifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if this.hmacKey != nil {
+			return this.hmacKey, nil
+		}
+	case *jwt.SigningMethodRSA:
+		if this.rsaKey != nil {
+			return this.rsaKey, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+}
+
+//Verify parses the token, checks its signature and expiry and returns the caller identity
+//tokens without an expiry are rejected, they would be valid forever
+func (this *Verifier) Verify(token string) (*Identity, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, this.key, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512"}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	identity := &Identity{Subject: claims.Subject, Roles: claims.Roles}
+	if userID, err := strconv.ParseInt(claims.Subject, 10, 32); err == nil {
+		identity.UserID = int32(userID)
+	}
+	return identity, nil
+}
+
+//PeerIdentity returns the identity of the verified TLS client certificate of the call
+//the common name is the subject and the organizational units are the roles
+func PeerIdentity(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	subject := tlsInfo.State.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return nil, false
+	}
+	identity := &Identity{Subject: subject.CommonName, Roles: subject.OrganizationalUnit}
+	if userID, err := strconv.ParseInt(subject.CommonName, 10, 32); err == nil {
+		identity.UserID = int32(userID)
+	}
+	return identity, true
+}
+
+//authenticate verifies the bearer token in the authorization metadata and falls back to the client certificate
+//without a verifier bearer tokens are ignored and calls without certificate pass without identity
+func authenticate(ctx context.Context, verifier *Verifier) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if verifier == nil || len(values) == 0 {
+		if identity, ok := PeerIdentity(ctx); ok {
+			return NewContext(ctx, identity), nil
+		}
+		if verifier == nil {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	const prefix = "bearer "
+	if len(values[0]) < len(prefix) || !strings.EqualFold(values[0][:len(prefix)], prefix) {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+	identity, err := verifier.Verify(values[0][len(prefix):])
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	return NewContext(ctx, identity), nil
+}
+
+//public reports whether the method is served without authentication
+//the grpc.* services are health checking and reflection
+func public(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.")
+}
+
+//UnaryServerInterceptor authenticates unary calls, verifier may be nil when only client certificates are used
+func UnaryServerInterceptor(verifier *Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, verifier)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+//StreamServerInterceptor authenticates streaming calls, verifier may be nil when only client certificates are used
+func StreamServerInterceptor(verifier *Verifier) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		ctx, err := authenticate(stream.Context(), verifier)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+//contextStream replaces the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (this *contextStream) Context() context.Context {
+	return this.ctx
+}
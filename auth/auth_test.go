@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var hmacKey = []byte("secret")
+
+func writeKeys(t *testing.T) (string, string, *rsa.PrivateKey) {
+	dir := t.TempDir()
+	hmacFile := filepath.Join(dir, "hmac.key")
+	if err := os.WriteFile(hmacFile, append(hmacKey, '\n'), 0600); err != nil {
+		t.Fatalf("cannot write hmac key %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate rsa key %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("cannot marshal rsa key %v", err)
+	}
+	rsaFile := filepath.Join(dir, "rsa.pem")
+	if err := os.WriteFile(rsaFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("cannot write rsa key %v", err)
+	}
+	return hmacFile, rsaFile, rsaKey
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims *Claims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("cannot sign token %v", err)
+	}
+	return token
+}
+
+func TestVerify(t *testing.T) {
+	hmacFile, rsaFile, rsaKey := writeKeys(t)
+	verifier, err := LoadVerifier(hmacFile, rsaFile)
+	if err != nil {
+		t.Fatalf("LoadVerifier() failed %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate rsa key %v", err)
+	}
+
+	expires := jwt.NewNumericDate(time.Now().Add(time.Hour))
+	testData := []struct {
+		desc    string
+		token   string
+		wantRes *Identity
+		wantErr bool
+	}{
+		{
+			desc:    "hmac user",
+			token:   sign(t, jwt.SigningMethodHS256, hmacKey, &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "3", ExpiresAt: expires}}),
+			wantRes: &Identity{Subject: "3", UserID: 3},
+			wantErr: false,
+		},
+		{
+			desc:    "rsa admin",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "ops", ExpiresAt: expires}, Roles: []string{AdminRole}}),
+			wantRes: &Identity{Subject: "ops", Roles: []string{AdminRole}},
+			wantErr: false,
+		},
+		{
+			desc:    "expired",
+			token:   sign(t, jwt.SigningMethodHS256, hmacKey, &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "3", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour))}}),
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			desc:    "no expiry",
+			token:   sign(t, jwt.SigningMethodHS256, hmacKey, &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "3"}}),
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			desc:    "wrong hmac key",
+			token:   sign(t, jwt.SigningMethodHS256, []byte("other"), &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "3"}}),
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			desc:    "wrong rsa key",
+			token:   sign(t, jwt.SigningMethodRS256, otherKey, &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "3"}}),
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			desc:    "no subject",
+			token:   sign(t, jwt.SigningMethodHS256, hmacKey, &Claims{}),
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			desc:    "not a token",
+			token:   "abc",
+			wantRes: nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testData {
+		got, err := verifier.Verify(tc.token)
+
+		if (err != nil) != tc.wantErr {
+			t.Errorf("[%q]: Verify() got error %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got); diff != "" {
+			t.Errorf("[%q]: Verify() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+func TestLoadVerifierWithoutKeys(t *testing.T) {
+	if _, err := LoadVerifier("", ""); err == nil {
+		t.Errorf("LoadVerifier() without keys succeeded, want error")
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	hmacFile, _, _ := writeKeys(t)
+	verifier, err := LoadVerifier(hmacFile, "")
+	if err != nil {
+		t.Fatalf("LoadVerifier() failed %v", err)
+	}
+	token := sign(t, jwt.SigningMethodHS256, hmacKey, &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "7", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}})
+
+	testData := []struct {
+		desc      string
+		method    string
+		md        metadata.MD
+		wantUser  int32
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{desc: "valid token", method: "/todo.TodoService/AddTodo", md: metadata.Pairs("authorization", "Bearer "+token), wantUser: 7, wantCode: codes.OK, wantCalls: 1},
+		{desc: "missing token", method: "/todo.TodoService/AddTodo", md: metadata.MD{}, wantCode: codes.Unauthenticated, wantCalls: 0},
+		{desc: "basic auth", method: "/todo.TodoService/AddTodo", md: metadata.Pairs("authorization", "Basic abc"), wantCode: codes.Unauthenticated, wantCalls: 0},
+		{desc: "health check", method: "/grpc.health.v1.Health/Check", md: metadata.MD{}, wantCode: codes.OK, wantCalls: 1},
+	}
+
+	interceptor := UnaryServerInterceptor(verifier)
+	for _, tc := range testData {
+		calls := 0
+		var gotUser int32
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			if identity, ok := FromContext(ctx); ok {
+				gotUser = identity.UserID
+			}
+			return nil, nil
+		}
+
+		ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: interceptor got code %v, want %v", tc.desc, code, tc.wantCode)
+		}
+		if calls != tc.wantCalls || gotUser != tc.wantUser {
+			t.Errorf("[%q]: handler got %d calls for user %d, want %d calls for user %d", tc.desc, calls, gotUser, tc.wantCalls, tc.wantUser)
+		}
+	}
+}
@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -9,12 +13,62 @@ import (
 	"strings"
 	"time"
 	"todo-app/todo"
+	"todo-app/tracing"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
+//transportCredentials returns TLS credentials verifying the server with the CA file,
+//or the system roots when it's empty, and presenting the client certificate when set
+//without any file the connection isn't encrypted
+func transportCredentials(caFile string, certFile string, keyFile string) (credentials.TransportCredentials, error) {
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("cert and key must be set together")
+	}
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in ca file %s", caFile)
+		}
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+//bearerToken sends the token in the authorization metadata of every call
+type bearerToken string
+
+func (this bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(this)}, nil
+}
+
+//RequireTransportSecurity keeps the token off unencrypted connections
+func (this bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
 func addTodo(ctx context.Context, todoService todo.TodoServiceClient, userID int32, todoItem string) {
-	message := &todo.AddTodoRequest{Item: &todo.TodoItem{UserID: userID, TodoID: -1, Todo: todoItem}}
+	message := &todo.AddTodoRequest{Item: &todo.TodoItem{UserID: userID, Todo: todoItem}}
 	response, err := todoService.AddTodo(ctx, message)
 
 	if err != nil {
@@ -25,71 +79,134 @@ func addTodo(ctx context.Context, todoService todo.TodoServiceClient, userID int
 }
 
 func getAllTodos(ctx context.Context, todoService todo.TodoServiceClient) {
-	todos, err := todoService.GetAllTodos(ctx, &todo.NoParams{})
+	message := &todo.GetAllTodosRequest{}
+	for {
+		todos, err := todoService.GetAllTodos(ctx, message)
 
-	if err != nil {
-		log.Printf("Error when calling get all todos %s", err)
+		if err != nil {
+			log.Printf("Error when calling get all todos %s", err)
+			return
+		}
+
+		log.Printf("Response From server: %s", todos)
+
+		if todos.NextPageToken == "" {
+			return
+		}
+		message.PageToken = todos.NextPageToken
+	}
+}
+
+//maxStreamAttempts is how often getAllTodosStreaming connects without receiving an item before giving up
+const maxStreamAttempts = 5
+
+//retryable reports whether a broken stream is worth resuming, network failures and server restarts
+//are reported as Unavailable and reset HTTP/2 streams as Internal
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal:
+		return true
 	}
+	return false
+}
 
-	log.Printf("Response From server: %s", todos)
+//getAllTodosStreaming streams all todos, a broken stream is resumed after the last received item
+//with exponential backoff, the attempts are reset whenever an item arrives
+func getAllTodosStreaming(ctx context.Context, todoService todo.TodoServiceClient, handle func(*todo.TodoItem), backoff time.Duration) error {
+	message := &todo.GetAllTodosStreamingRequest{}
+	attempts := 0
+	for {
+		err := receiveAllTodos(ctx, todoService, message, func(response *todo.GetAllTodosStreamingResponse) {
+			handle(response.Item)
+			message.ResumeAfter = response.Cursor
+			attempts = 0
+		})
+		if err == nil {
+			return nil
+		}
+		attempts++
+		if !retryable(err) || attempts >= maxStreamAttempts {
+			return err
+		}
+		wait := backoff << (attempts - 1)
+		log.Printf("Stream broken %s, resuming in %v", err, wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
-func getAllTodosStreaming(ctx context.Context, todoService todo.TodoServiceClient) {
-	stream, err := todoService.GetAllTodosStreaming(ctx, &todo.NoParams{}, grpc.EmptyCallOption{})
+//receiveAllTodos opens one stream and passes its responses to handle until it ends
+func receiveAllTodos(ctx context.Context, todoService todo.TodoServiceClient, message *todo.GetAllTodosStreamingRequest, handle func(*todo.GetAllTodosStreamingResponse)) error {
+	stream, err := todoService.GetAllTodosStreamingWithCursor(ctx, message)
 	if err != nil {
-		log.Printf("Error couldn't init stream %s", err)
+		return err
 	}
 	for {
-		item, err := stream.Recv()
+		response, err := stream.Recv()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			log.Printf("Error in get all todos streaming %s", err)
+			return err
 		}
-		log.Println("Received ", item)
+		handle(response)
 	}
 }
 
+//getUserTodos reads every page of the todos of each user over one stream, the server caps the size of a page
 func getUserTodos(ctx context.Context, todoService todo.TodoServiceClient, userIDS []int32) []*todo.TodoItem {
 	todos := make([]*todo.TodoItem, 0)
 	stream, err := todoService.GetUserTodos(ctx, grpc.EmptyCallOption{})
 	if err != nil {
 		log.Printf("Error couldn't init stream %s", err)
+		return todos
 	}
-	waitc := make(chan struct{})
-	go func() {
+	for _, id := range userIDS {
+		message := &todo.GetUserTodosRequest{UserID: id}
 		for {
-			message, err := stream.Recv()
-			if err == io.EOF {
-				close(waitc)
-				return
+			log.Println("Sending ", message)
+			if err := stream.Send(message); err != nil {
+				log.Printf("Error sending %s", err)
+				return todos
 			}
+			response, err := stream.Recv()
 			if err != nil {
 				log.Printf("Error receiving %s", err)
-				close(waitc)
-				return
+				return todos
 			}
-			userTodoItems := message.Items
-			log.Println("Received ", userTodoItems)
-			for _, todoItem := range userTodoItems {
-				todos = append(todos, todoItem)
+			log.Println("Received ", response.Items)
+			todos = append(todos, response.Items...)
+			if response.NextPageToken == "" {
+				break
 			}
+			message = &todo.GetUserTodosRequest{UserID: id, PageToken: response.NextPageToken}
 		}
-	}()
-	for _, id := range userIDS {
-		log.Println("Sending ", id)
-		stream.Send(&todo.GetUserTodosRequest{UserID: id})
 	}
 	log.Println("Closing client")
 	if err := stream.CloseSend(); err != nil {
 		log.Printf("Failed to close")
 	}
 	log.Println("Closed")
-	<-waitc
 	return todos
 }
 
+//getTodo prints the todo item and its etag, or that it's unchanged when etag matches
+func getTodo(ctx context.Context, todoService todo.TodoServiceClient, todoID int32, etag string) {
+	response, err := todoService.GetTodo(ctx, &todo.GetTodoRequest{TodoID: todoID, IfNoneMatch: etag})
+	if err != nil {
+		log.Printf("Error %s", err)
+		return
+	}
+	if response.NotModified {
+		log.Println("Not modified, etag", response.Etag)
+		return
+	}
+	log.Println("Response ", response.Item, "etag", response.Etag)
+}
+
 func deleteUserTodos(ctx context.Context, todoService todo.TodoServiceClient, userID int32) {
 	message := &todo.DeleteUserTodosRequest{UserID: userID}
 	_, err := todoService.DeleteUserTodos(ctx, message)
@@ -101,8 +218,8 @@ func deleteUserTodos(ctx context.Context, todoService todo.TodoServiceClient, us
 	log.Printf("Deleted")
 }
 
-func getUserTodosWithHash(ctx context.Context, todoService todo.TodoServiceClient, userID int32, timeOut time.Duration) {
-	message := &todo.GetUserTodoItemsWithHashRequest{UserID: userID}
+func getUserTodosWithHash(ctx context.Context, todoService todo.TodoServiceClient, userID int32, timeOut time.Duration, algorithm string, transform string) {
+	message := &todo.GetUserTodoItemsWithHashRequest{UserID: userID, Algorithm: algorithm, Transform: transform}
 	childContext, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 	response, err := todoService.GetUserTodoItemsWithHash(childContext, message)
@@ -115,16 +232,86 @@ func getUserTodosWithHash(ctx context.Context, todoService todo.TodoServiceClien
 	}
 }
 
+//streamUserTodosWithHash prints each hashed item as soon as the server sends it, the items sent before the timeout are kept
+func streamUserTodosWithHash(ctx context.Context, todoService todo.TodoServiceClient, userID int32, timeOut time.Duration, ordered bool) {
+	message := &todo.StreamUserTodoItemsWithHashRequest{UserID: userID, Ordered: ordered}
+	childContext, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+	stream, err := todoService.StreamUserTodoItemsWithHash(childContext, message)
+	if err != nil {
+		log.Printf("Error couldn't init stream %s", err)
+		return
+	}
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Printf("Error %s", err)
+			return
+		}
+		log.Println("Response ", item)
+	}
+}
+
+func watchTodos(ctx context.Context, todoService todo.TodoServiceClient, userID int32) {
+	stream, err := todoService.WatchTodos(ctx, &todo.WatchTodosRequest{UserID: userID})
+	if err != nil {
+		log.Printf("Error couldn't init stream %s", err)
+		return
+	}
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			log.Printf("Error in watch todos %s", err)
+			return
+		}
+		log.Println("Received ", event)
+	}
+}
+
 func main() {
+	address := flag.String("address", ":9000", "address of the todo server")
+	caFile := flag.String("ca", "", "CA file verifying the server certificate, enables TLS")
+	certFile := flag.String("cert", "", "client certificate file for mutual TLS")
+	keyFile := flag.String("key", "", "client private key file for mutual TLS")
+	traceExporter := flag.String("trace-exporter", "none", "trace exporter, none, stdout or otlp")
+	traceEndpoint := flag.String("trace-endpoint", "", "OTLP gRPC collector address of the otlp trace exporter")
+	tokenFile := flag.String("token", "", "file with a JWT bearer token sent with every call, requires TLS")
+	flag.Parse()
 
 	//get arguments
-	if len(os.Args) == 1 {
+	args := flag.Args()
+	if len(args) == 0 {
 		log.Printf("You must specify arguments")
 		return
 	}
 
-	//init connection
-	conn, err := grpc.Dial(":9000", grpc.WithInsecure())
+	creds, err := transportCredentials(*caFile, *certFile, *keyFile)
+	if err != nil {
+		log.Printf("Error when loading TLS credentials %s", err)
+		return
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "todo-client", *traceExporter, *traceEndpoint)
+	if err != nil {
+		log.Printf("Error when setting up tracing %s", err)
+		return
+	}
+	defer shutdownTracing(context.Background())
+
+	//init connection, the stats handler sends the W3C trace context with every call
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds), grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
+	if *tokenFile != "" {
+		token, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			log.Printf("Error when reading token %s", err)
+			return
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(strings.TrimSpace(string(token)))))
+	}
+	conn, err := grpc.Dial(*address, opts...)
 	if err != nil {
 		log.Printf("Could not connect %s", err)
 	}
@@ -133,47 +320,53 @@ func main() {
 	//get todo service
 	todoService := todo.NewTodoServiceClient(conn)
 
-	//creating context
-	ctx := context.Background()
+	//creating context, the calls of a command share one trace
+	ctx, span := otel.Tracer("todo-app/client").Start(context.Background(), args[0])
+	defer span.End()
 
 	//add todo
 	//command : !add userID todoItem
-	if os.Args[1] == "add" {
-		if len(os.Args) <= 3 {
+	if args[0] == "add" {
+		if len(args) <= 2 {
 			log.Println("Invalid arguments")
 			return
 		}
-		userID, err := strconv.Atoi(os.Args[2])
+		userID, err := strconv.Atoi(args[1])
 		if err != nil {
 			log.Println("User id must be a number")
 			return
 		}
-		todoItem := strings.Join(os.Args[3:], " ")
+		todoItem := strings.Join(args[2:], " ")
 		addTodo(ctx, todoService, int32(userID), todoItem)
 	}
 
 	//get all todos
 	//command : !get_all
-	if os.Args[1] == "get_all" {
+	if args[0] == "get_all" {
 		getAllTodos(ctx, todoService)
 	}
 
 	//get all todos streaming
 	//command : !get_all_streaming
-	if os.Args[1] == "get_all_streaming" {
-		getAllTodosStreaming(ctx, todoService)
+	if args[0] == "get_all_streaming" {
+		printItem := func(item *todo.TodoItem) {
+			log.Println("Received ", item)
+		}
+		if err := getAllTodosStreaming(ctx, todoService, printItem, 100*time.Millisecond); err != nil {
+			log.Printf("Error in get all todos streaming %s", err)
+		}
 	}
 
 	//get_user_todos
 	//command : !get_user_todos
-	if os.Args[1] == "get_user_todos" {
-		if len(os.Args) <= 2 {
+	if args[0] == "get_user_todos" {
+		if len(args) <= 1 {
 			log.Println("Invalid arguments")
 			return
 		}
 		userIDS := make([]int32, 0)
-		for i := 2; i < len(os.Args); i++ {
-			if val, err := strconv.Atoi(os.Args[i]); err != nil {
+		for i := 1; i < len(args); i++ {
+			if val, err := strconv.Atoi(args[i]); err != nil {
 				log.Println("Invalid arguments")
 				return
 			} else {
@@ -187,14 +380,33 @@ func main() {
 		}
 	}
 
+	//get todo
+	//command : !get todoID [etag]
+	if args[0] == "get" {
+		if len(args) <= 1 {
+			log.Println("Invalid arguments")
+			return
+		}
+		todoID, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Println("Invalid arguments")
+			return
+		}
+		etag := ""
+		if len(args) > 2 {
+			etag = args[2]
+		}
+		getTodo(ctx, todoService, int32(todoID), etag)
+	}
+
 	//delete user todos
 	//command : !delete userID
-	if os.Args[1] == "delete" {
-		if len(os.Args) <= 2 {
+	if args[0] == "delete" {
+		if len(args) <= 1 {
 			log.Println("Invalid arguments")
 			return
 		}
-		userID, err := strconv.Atoi(os.Args[2])
+		userID, err := strconv.Atoi(args[1])
 		if err != nil {
 			log.Println("Invalid arguments")
 			return
@@ -202,21 +414,67 @@ func main() {
 		deleteUserTodos(ctx, todoService, int32(userID))
 	}
 
-	if os.Args[1] == "get_user_todos_hash" {
-		if len(os.Args) < 4 {
+	//watch user todos
+	//command : !watch userID
+	if args[0] == "watch" {
+		if len(args) <= 1 {
+			log.Println("Invalid arguments")
+			return
+		}
+		userID, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Println("Invalid arguments")
+			return
+		}
+		watchTodos(ctx, todoService, int32(userID))
+	}
+
+	//get user todos with hash
+	//command : !get_user_todos_hash userID timeout [algorithm] [transform]
+	if args[0] == "get_user_todos_hash" {
+		if len(args) < 3 {
+			log.Println("Invalid arguments")
+			return
+		}
+		userID, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Println("Invalid arguments")
+			return
+		}
+		timeOut, err := strconv.Atoi(args[2])
+		if err != nil {
+			log.Println("Invalid arguments")
+			return
+		}
+		algorithm := ""
+		if len(args) > 3 {
+			algorithm = args[3]
+		}
+		transform := ""
+		if len(args) > 4 {
+			transform = args[4]
+		}
+		getUserTodosWithHash(ctx, todoService, int32(userID), time.Millisecond*time.Duration(timeOut), algorithm, transform)
+	}
+
+	//stream user todos with hash
+	//command : !stream_user_todos_hash userID timeout [ordered]
+	if args[0] == "stream_user_todos_hash" {
+		if len(args) < 3 {
 			log.Println("Invalid arguments")
 			return
 		}
-		userID, err := strconv.Atoi(os.Args[2])
+		userID, err := strconv.Atoi(args[1])
 		if err != nil {
 			log.Println("Invalid arguments")
 			return
 		}
-		timeOut, err := strconv.Atoi(os.Args[3])
+		timeOut, err := strconv.Atoi(args[2])
 		if err != nil {
 			log.Println("Invalid arguments")
 			return
 		}
-		getUserTodosWithHash(ctx, todoService, int32(userID), time.Millisecond*time.Duration(timeOut))
+		ordered := len(args) > 3 && args[3] == "ordered"
+		streamUserTodosWithHash(ctx, todoService, int32(userID), time.Millisecond*time.Duration(timeOut), ordered)
 	}
 }
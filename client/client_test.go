@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"todo-app/todo"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//flakyClient serves todos 1 to count and breaks every stream after breakAfter items
+type flakyClient struct {
+	todo.TodoServiceClient
+	count      int32
+	breakAfter int
+	breakCode  codes.Code
+	requests   []string
+}
+
+func (this *flakyClient) GetAllTodosStreamingWithCursor(ctx context.Context, in *todo.GetAllTodosStreamingRequest, opts ...grpc.CallOption) (todo.TodoService_GetAllTodosStreamingWithCursorClient, error) {
+	this.requests = append(this.requests, in.ResumeAfter)
+	var after int32
+	for id := int32(1); id <= this.count; id++ {
+		if cursor(id) == in.ResumeAfter {
+			after = id
+		}
+	}
+	return &flakyStream{client: this, next: after + 1}, nil
+}
+
+func cursor(id int32) string {
+	return "cursor-" + strconv.Itoa(int(id))
+}
+
+type flakyStream struct {
+	grpc.ClientStream
+	client *flakyClient
+	next   int32
+	sent   int
+}
+
+func (this *flakyStream) Recv() (*todo.GetAllTodosStreamingResponse, error) {
+	if this.next > this.client.count {
+		return nil, io.EOF
+	}
+	if this.sent == this.client.breakAfter {
+		return nil, status.Error(this.client.breakCode, "connection reset")
+	}
+	this.sent++
+	this.next++
+	return &todo.GetAllTodosStreamingResponse{Item: &todo.TodoItem{TodoID: this.next - 1}, Cursor: cursor(this.next - 1)}, nil
+}
+
+func TestGetAllTodosStreamingResumes(t *testing.T) {
+	testData := []struct {
+		desc         string
+		client       *flakyClient
+		wantIDs      []int32
+		wantRequests []string
+		wantErr      bool
+	}{
+		{
+			desc:         "unbroken stream",
+			client:       &flakyClient{count: 3, breakAfter: -1},
+			wantIDs:      []int32{1, 2, 3},
+			wantRequests: []string{""},
+			wantErr:      false,
+		},
+		{
+			desc:         "resumes after each broken stream",
+			client:       &flakyClient{count: 5, breakAfter: 2, breakCode: codes.Unavailable},
+			wantIDs:      []int32{1, 2, 3, 4, 5},
+			wantRequests: []string{"", cursor(2), cursor(4)},
+			wantErr:      false,
+		},
+		{
+			desc:         "gives up without progress",
+			client:       &flakyClient{count: 5, breakAfter: 0, breakCode: codes.Unavailable},
+			wantIDs:      nil,
+			wantRequests: []string{"", "", "", "", ""},
+			wantErr:      true,
+		},
+		{
+			desc:         "doesn't retry permanent errors",
+			client:       &flakyClient{count: 5, breakAfter: 1, breakCode: codes.PermissionDenied},
+			wantIDs:      []int32{1},
+			wantRequests: []string{""},
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range testData {
+		var ids []int32
+		handle := func(item *todo.TodoItem) {
+			ids = append(ids, item.TodoID)
+		}
+
+		err := getAllTodosStreaming(context.Background(), tc.client, handle, 0)
+
+		if (err != nil) != tc.wantErr {
+			t.Errorf("[%q]: getAllTodosStreaming() got error %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tc.wantIDs, ids); diff != "" {
+			t.Errorf("[%q]: getAllTodosStreaming() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+		if diff := cmp.Diff(tc.wantRequests, tc.client.requests); diff != "" {
+			t.Errorf("[%q]: getAllTodosStreaming() sent unexpected requests diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+//pagedClient serves count todos of every user in pages of pageSize
+type pagedClient struct {
+	todo.TodoServiceClient
+	count    int32
+	pageSize int32
+	requests []*todo.GetUserTodosRequest
+}
+
+func (this *pagedClient) GetUserTodos(ctx context.Context, opts ...grpc.CallOption) (todo.TodoService_GetUserTodosClient, error) {
+	return &pagedStream{client: this}, nil
+}
+
+type pagedStream struct {
+	grpc.ClientStream
+	client *pagedClient
+}
+
+func (this *pagedStream) Send(message *todo.GetUserTodosRequest) error {
+	this.client.requests = append(this.client.requests, message)
+	return nil
+}
+
+func (this *pagedStream) Recv() (*todo.GetUserTodosResponse, error) {
+	message := this.client.requests[len(this.client.requests)-1]
+	first := int32(1)
+	if message.PageToken != "" {
+		after, _ := strconv.Atoi(message.PageToken)
+		first = int32(after) + 1
+	}
+	response := &todo.GetUserTodosResponse{}
+	for id := first; id <= this.client.count && id < first+this.client.pageSize; id++ {
+		response.Items = append(response.Items, &todo.TodoItem{TodoID: id, UserID: message.UserID})
+	}
+	if last := first + this.client.pageSize - 1; last < this.client.count {
+		response.NextPageToken = strconv.Itoa(int(last))
+	}
+	return response, nil
+}
+
+func (this *pagedStream) CloseSend() error {
+	return nil
+}
+
+func TestGetUserTodosFollowsPages(t *testing.T) {
+	client := &pagedClient{count: 5, pageSize: 2}
+
+	got := getUserTodos(context.Background(), client, []int32{1, 2})
+
+	if len(got) != 10 || got[4].UserID != 1 || got[5].UserID != 2 || got[9].TodoID != 5 {
+		t.Errorf("getUserTodos() got %v, want todos 1 to 5 of users 1 and 2", got)
+	}
+	want := []string{"", "2", "4", "", "2", "4"}
+	var tokens []string
+	for _, request := range client.requests {
+		tokens = append(tokens, request.PageToken)
+	}
+	if diff := cmp.Diff(want, tokens); diff != "" {
+		t.Errorf("getUserTodos() sent unexpected page tokens diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	token := bearerToken("abc.def.ghi")
+
+	md, err := token.GetRequestMetadata(context.Background())
+
+	if err != nil {
+		t.Fatalf("GetRequestMetadata() got error %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"authorization": "Bearer abc.def.ghi"}, md); diff != "" {
+		t.Errorf("GetRequestMetadata() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+	if !token.RequireTransportSecurity() {
+		t.Errorf("RequireTransportSecurity() got false, want the token kept off plaintext connections")
+	}
+}
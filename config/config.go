@@ -0,0 +1,391 @@
+//Package config loads the server configuration from defaults, an optional YAML file,
+//environment variables and command line flags, later sources override earlier ones
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//envPrefix of the environment variables, e.g. TODO_LISTEN_ADDRESS
+const envPrefix = "TODO_"
+
+//TLS files are reloaded when they change on disk
+type TLS struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	//ClientCAFile enables mutual TLS, clients must present a certificate signed by one of its CAs
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
+//Enabled reports whether the server should serve TLS
+func (this TLS) Enabled() bool {
+	return this.CertFile != "" || this.KeyFile != ""
+}
+
+//Mutual reports whether clients must authenticate with a certificate
+func (this TLS) Mutual() bool {
+	return this.ClientCAFile != ""
+}
+
+type Auth struct {
+	//HMACKeyFile holds the shared secret of HS256, HS384 and HS512 tokens
+	HMACKeyFile string `yaml:"hmac_key_file"`
+	//RSAPublicKeyFile holds the PEM public key of RS256, RS384 and RS512 tokens
+	RSAPublicKeyFile string `yaml:"rsa_public_key_file"`
+}
+
+//Enabled reports whether callers must authenticate with a bearer token
+func (this Auth) Enabled() bool {
+	return this.HMACKeyFile != "" || this.RSAPublicKeyFile != ""
+}
+
+type Tracing struct {
+	//Exporter is none, stdout or otlp
+	Exporter string `yaml:"exporter"`
+	//Endpoint of the OTLP gRPC collector, the OTEL_EXPORTER_OTLP_* environment variables apply when it's empty
+	Endpoint string `yaml:"endpoint"`
+}
+
+//Limit is a token bucket refilled with Rate tokens per second holding up to Burst tokens, a zero rate is unlimited
+type Limit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+//Unlimited reports whether the limit lets every call pass
+func (this Limit) Unlimited() bool {
+	return this.Rate == 0
+}
+
+//RateLimit is only set in the YAML file, it is reloaded on SIGHUP
+type RateLimit struct {
+	//Global is shared by all callers
+	Global Limit `yaml:"global"`
+	//Default is the budget of each caller and method without an entry in Methods
+	Default Limit `yaml:"default"`
+	//Methods maps method names, e.g. AddTodo, to the budget of each caller
+	Methods map[string]Limit `yaml:"methods"`
+	//MaxStreams caps the open streams of each caller, zero is unlimited
+	MaxStreams int `yaml:"max_streams"`
+}
+
+type Log struct {
+	//Level is debug, info, warn or error
+	Level string `yaml:"level"`
+	//Format is text or json
+	Format string `yaml:"format"`
+	//TodoContent logs the text of todo items, it is redacted by default because it is user content
+	TodoContent bool `yaml:"todo_content"`
+}
+
+type Config struct {
+	ListenAddress string `yaml:"listen_address"`
+	//MetricsAddress serves /metrics on a separate admin port, empty disables it
+	MetricsAddress string `yaml:"metrics_address"`
+	//Store is mysql, sqlite or memory
+	Store       string        `yaml:"store"`
+	MySQLDSN    string        `yaml:"mysql_dsn"`
+	SQLitePath  string        `yaml:"sqlite_path"`
+	WaitingTime time.Duration `yaml:"waiting_time"`
+	//ShutdownTimeout is how long open streams may run after a shutdown signal before they are stopped
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	//HashAlgorithm is used by hash requests that don't choose one
+	HashAlgorithm string `yaml:"hash_algorithm"`
+	//HashTransform is the strategy collecting hashed todos of requests that don't choose one
+	HashTransform string `yaml:"hash_transform"`
+	//HashWorkers caps the goroutines hashing the todos of one request, zero is one per todo
+	HashWorkers int `yaml:"hash_workers"`
+	//HashWorkersTotal caps the hash goroutines of all requests together, zero is unlimited
+	HashWorkersTotal int `yaml:"hash_workers_total"`
+	//HealthInterval is how often the data store is pinged to update the health status
+	HealthInterval time.Duration `yaml:"health_interval"`
+	TLS            TLS           `yaml:"tls"`
+	Auth           Auth          `yaml:"auth"`
+	Tracing        Tracing       `yaml:"tracing"`
+	RateLimit      RateLimit     `yaml:"rate_limit"`
+	Log            Log           `yaml:"log"`
+}
+
+//Default returns the configuration used when nothing overrides it
+func Default() Config {
+	return Config{
+		ListenAddress:    ":9000",
+		MetricsAddress:   ":9090",
+		Store:            "mysql",
+		MySQLDSN:         "root:pass123@tcp(127.0.0.1:3306)/testdb",
+		SQLitePath:       "todos.db",
+		WaitingTime:      time.Second,
+		ShutdownTimeout:  10 * time.Second,
+		HealthInterval:   5 * time.Second,
+		HashAlgorithm:    "fnv1a",
+		HashTransform:    "indexing",
+		HashWorkers:      64,
+		HashWorkersTotal: 1024,
+		Tracing:          Tracing{Exporter: "none"},
+		Log:              Log{Level: "info", Format: "text"},
+	}
+}
+
+//setting binds one configuration value to a flag, an environment variable and a YAML key
+type setting struct {
+	name  string
+	usage string
+	value flag.Value
+}
+
+func settings(cfg *Config) []setting {
+	return []setting{
+		{"listen-address", "address the gRPC server listens on", (*stringValue)(&cfg.ListenAddress)},
+		{"metrics-address", "admin address serving Prometheus metrics, empty disables it", (*stringValue)(&cfg.MetricsAddress)},
+		{"store", "data store backend, mysql, sqlite or memory", (*stringValue)(&cfg.Store)},
+		{"mysql-dsn", "data source name of the mysql store", (*stringValue)(&cfg.MySQLDSN)},
+		{"sqlite-path", "database file of the sqlite store", (*stringValue)(&cfg.SQLitePath)},
+		{"waiting-time", "delay between streamed messages", (*durationValue)(&cfg.WaitingTime)},
+		{"hash-algorithm", "default hash algorithm of todo items, fnv1a, xxhash, sha256 or crc32", (*stringValue)(&cfg.HashAlgorithm)},
+		{"hash-transform", "default strategy collecting hashed todos, indexing, pointer, append, append_preallocated, append_channels or indexing_channels", (*stringValue)(&cfg.HashTransform)},
+		{"hash-workers", "goroutines hashing the todos of one request, 0 is one per todo", (*intValue)(&cfg.HashWorkers)},
+		{"hash-workers-total", "hash goroutines of all requests together, 0 is unlimited", (*intValue)(&cfg.HashWorkersTotal)},
+		{"health-interval", "how often the data store is pinged for health checks", (*durationValue)(&cfg.HealthInterval)},
+		{"shutdown-timeout", "time open streams get to finish after SIGINT or SIGTERM", (*durationValue)(&cfg.ShutdownTimeout)},
+		{"tls-cert", "TLS certificate file, enables TLS", (*stringValue)(&cfg.TLS.CertFile)},
+		{"tls-key", "TLS private key file", (*stringValue)(&cfg.TLS.KeyFile)},
+		{"tls-client-ca", "CA file of client certificates, enables mutual TLS", (*stringValue)(&cfg.TLS.ClientCAFile)},
+		{"auth-hmac-key", "HMAC secret file of JWT bearer tokens, enables authentication", (*stringValue)(&cfg.Auth.HMACKeyFile)},
+		{"auth-rsa-public-key", "RSA public key PEM file of JWT bearer tokens, enables authentication", (*stringValue)(&cfg.Auth.RSAPublicKeyFile)},
+		{"trace-exporter", "trace exporter, none, stdout or otlp", (*stringValue)(&cfg.Tracing.Exporter)},
+		{"trace-endpoint", "OTLP gRPC collector address of the otlp trace exporter", (*stringValue)(&cfg.Tracing.Endpoint)},
+		{"log-level", "log level, debug, info, warn or error", (*stringValue)(&cfg.Log.Level)},
+		{"log-format", "log format, text or json", (*stringValue)(&cfg.Log.Format)},
+		{"log-todo-content", "log the text of todo items instead of redacting it", (*boolValue)(&cfg.Log.TodoContent)},
+	}
+}
+
+//envName returns the environment variable of a setting, e.g. TODO_LISTEN_ADDRESS
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+//Load parses the flags in args and returns the configuration and the remaining arguments
+//the YAML file is given by -config or TODO_CONFIG
+func Load(fs *flag.FlagSet, args []string) (*Config, []string, error) {
+	return load(fs, args, os.LookupEnv)
+}
+
+func load(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	//flags are parsed into a separate config and copied over the file and environment values afterwards
+	flagCfg := Default()
+	flagSettings := settings(&flagCfg)
+	configFile := fs.String("config", "", "YAML configuration file, also "+envPrefix+"CONFIG")
+	for _, s := range flagSettings {
+		fs.Var(s.value, s.name, s.usage+", also "+envName(s.name))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := Default()
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv(envPrefix + "CONFIG")
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cfgSettings := settings(&cfg)
+	for _, s := range cfgSettings {
+		if value, ok := lookupEnv(envName(s.name)); ok {
+			if err := s.value.Set(value); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s %q: %v", envName(s.name), value, err)
+			}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		for i, s := range flagSettings {
+			if s.name == f.Name {
+				cfgSettings[i].value.Set(s.value.String())
+			}
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return &cfg, fs.Args(), nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("config file %s: %v", path, err)
+	}
+	return nil
+}
+
+//Validate checks the configuration values and reports all invalid ones
+func (this *Config) Validate() error {
+	var errs []string
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(this.ListenAddress); err != nil {
+		invalid("listen address %q: %v", this.ListenAddress, err)
+	}
+	if this.MetricsAddress != "" {
+		if _, _, err := net.SplitHostPort(this.MetricsAddress); err != nil {
+			invalid("metrics address %q: %v", this.MetricsAddress, err)
+		} else if this.MetricsAddress == this.ListenAddress {
+			invalid("metrics address %q must differ from the listen address", this.MetricsAddress)
+		}
+	}
+	switch this.Store {
+	case "mysql":
+		if this.MySQLDSN == "" {
+			invalid("mysql dsn must be set for the mysql store")
+		}
+	case "sqlite":
+		if this.SQLitePath == "" {
+			invalid("sqlite path must be set for the sqlite store")
+		}
+	case "memory":
+	default:
+		invalid("store %q must be mysql, sqlite or memory", this.Store)
+	}
+	//the streaming handlers tick every waiting time, time.NewTicker panics on zero
+	if this.WaitingTime <= 0 {
+		invalid("waiting time %v must be positive", this.WaitingTime)
+	}
+	if this.ShutdownTimeout < 0 {
+		invalid("shutdown timeout %v must not be negative", this.ShutdownTimeout)
+	}
+	if this.HashWorkers < 0 {
+		invalid("hash workers %d must not be negative", this.HashWorkers)
+	}
+	if this.HashWorkersTotal < 0 {
+		invalid("hash workers total %d must not be negative", this.HashWorkersTotal)
+	}
+	if this.HealthInterval <= 0 {
+		invalid("health interval %v must be positive", this.HealthInterval)
+	}
+	if (this.TLS.CertFile == "") != (this.TLS.KeyFile == "") {
+		invalid("tls cert and key must be set together")
+	}
+	if this.TLS.Mutual() && !this.TLS.Enabled() {
+		invalid("tls client ca requires tls cert and key")
+	}
+	checkLimit := func(name string, limit Limit) {
+		if limit.Rate < 0 || limit.Burst < 0 {
+			invalid("rate limit %s must not be negative", name)
+		} else if !limit.Unlimited() && limit.Burst == 0 {
+			invalid("rate limit %s needs a positive burst", name)
+		}
+	}
+	checkLimit("global", this.RateLimit.Global)
+	checkLimit("default", this.RateLimit.Default)
+	for method, limit := range this.RateLimit.Methods {
+		checkLimit(method, limit)
+	}
+	if this.RateLimit.MaxStreams < 0 {
+		invalid("rate limit max streams %d must not be negative", this.RateLimit.MaxStreams)
+	}
+	switch this.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		invalid("trace exporter %q must be none, stdout or otlp", this.Tracing.Exporter)
+	}
+	switch this.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		invalid("log level %q must be debug, info, warn or error", this.Log.Level)
+	}
+	switch this.Log.Format {
+	case "text", "json":
+	default:
+		invalid("log format %q must be text or json", this.Log.Format)
+	}
+
+	if len(errs) > 0 {
+		return errors.New("invalid config: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+type stringValue string
+
+func (this *stringValue) Set(value string) error {
+	*this = stringValue(value)
+	return nil
+}
+
+func (this *stringValue) String() string {
+	return string(*this)
+}
+
+type intValue int
+
+func (this *intValue) Set(value string) error {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*this = intValue(i)
+	return nil
+}
+
+func (this *intValue) String() string {
+	return strconv.Itoa(int(*this))
+}
+
+type boolValue bool
+
+func (this *boolValue) Set(value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*this = boolValue(b)
+	return nil
+}
+
+func (this *boolValue) String() string {
+	return strconv.FormatBool(bool(*this))
+}
+
+//IsBoolFlag allows -log-todo-content without a value
+func (this *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+type durationValue time.Duration
+
+func (this *durationValue) Set(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*this = durationValue(d)
+	return nil
+}
+
+func (this *durationValue) String() string {
+	return time.Duration(*this).String()
+}
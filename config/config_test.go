@@ -0,0 +1,312 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("cannot write config file %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	file := writeFile(t, "listen_address: \":9100\"\nstore: sqlite\nwaiting_time: 250ms\nlog:\n  format: json\n")
+
+	withDefaults := func(update func(*Config)) *Config {
+		cfg := Default()
+		update(&cfg)
+		return &cfg
+	}
+
+	testData := []struct {
+		desc     string
+		args     []string
+		env      map[string]string
+		wantRes  *Config
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			desc:     "defaults",
+			args:     []string{},
+			env:      map[string]string{},
+			wantRes:  withDefaults(func(cfg *Config) {}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc: "file",
+			args: []string{"-config", file},
+			env:  map[string]string{},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.ListenAddress = ":9100"
+				cfg.Store = "sqlite"
+				cfg.WaitingTime = 250 * time.Millisecond
+				cfg.Log.Format = "json"
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc: "env overrides file",
+			args: []string{},
+			env:  map[string]string{"TODO_CONFIG": file, "TODO_STORE": "memory", "TODO_WAITING_TIME": "2s"},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.ListenAddress = ":9100"
+				cfg.Store = "memory"
+				cfg.WaitingTime = 2 * time.Second
+				cfg.Log.Format = "json"
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc: "flags override env",
+			args: []string{"-config", file, "-store", "mysql", "-listen-address", ":9200", "migrate", "up"},
+			env:  map[string]string{"TODO_STORE": "memory", "TODO_LISTEN_ADDRESS": ":9300"},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.ListenAddress = ":9200"
+				cfg.WaitingTime = 250 * time.Millisecond
+				cfg.Log.Format = "json"
+			}),
+			wantArgs: []string{"migrate", "up"},
+			wantErr:  false,
+		},
+		{
+			desc: "auth keys",
+			args: []string{"-auth-hmac-key", "hmac.key"},
+			env:  map[string]string{"TODO_AUTH_RSA_PUBLIC_KEY": "rsa.pem"},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.Auth = Auth{HMACKeyFile: "hmac.key", RSAPublicKeyFile: "rsa.pem"}
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc: "metrics disabled",
+			args: []string{"-metrics-address", ""},
+			env:  map[string]string{},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.MetricsAddress = ""
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc:    "metrics on the listen address",
+			args:    []string{"-metrics-address", ":9000"},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc: "tracing",
+			args: []string{"-trace-exporter", "otlp"},
+			env:  map[string]string{"TODO_TRACE_ENDPOINT": "collector:4317"},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.Tracing = Tracing{Exporter: "otlp", Endpoint: "collector:4317"}
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc:    "invalid trace exporter",
+			args:    []string{"-trace-exporter", "jaeger"},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc: "log todo content",
+			args: []string{"-log-todo-content"},
+			env:  map[string]string{"TODO_LOG_FORMAT": "json"},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.Log = Log{Level: "info", Format: "json", TodoContent: true}
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc:    "invalid env bool",
+			args:    []string{},
+			env:     map[string]string{"TODO_LOG_TODO_CONTENT": "sometimes"},
+			wantErr: true,
+		},
+		{
+			desc: "hash workers",
+			args: []string{"-hash-workers", "8"},
+			env:  map[string]string{"TODO_HASH_WORKERS_TOTAL": "0"},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.HashWorkers = 8
+				cfg.HashWorkersTotal = 0
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc: "hash transform",
+			args: []string{"-hash-transform", "append_channels"},
+			env:  map[string]string{},
+			wantRes: withDefaults(func(cfg *Config) {
+				cfg.HashTransform = "append_channels"
+			}),
+			wantArgs: []string{},
+			wantErr:  false,
+		},
+		{
+			desc:    "negative hash workers",
+			args:    []string{"-hash-workers", "-1"},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid env duration",
+			args:    []string{},
+			env:     map[string]string{"TODO_WAITING_TIME": "soon"},
+			wantErr: true,
+		},
+		{
+			desc:    "zero waiting time",
+			args:    []string{"-waiting-time", "0s"},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc:    "zero env waiting time",
+			args:    []string{},
+			env:     map[string]string{"TODO_WAITING_TIME": "0s"},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid store",
+			args:    []string{"-store", "postgres"},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc:    "tls key without cert",
+			args:    []string{"-tls-key", "server.key"},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc:    "tls client ca without cert",
+			args:    []string{"-tls-client-ca", "ca.pem"},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc:    "missing config file",
+			args:    []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			env:     map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testData {
+		fs := flag.NewFlagSet("server", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		lookupEnv := func(key string) (string, bool) {
+			value, ok := tc.env[key]
+			return value, ok
+		}
+
+		got, gotArgs, err := load(fs, tc.args, lookupEnv)
+
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("[%q]: Load() got success, want an error", tc.desc)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("[%q]: Load() got error %v, want success", tc.desc, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got); diff != "" {
+			t.Errorf("[%q]: Load() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+
+		if diff := cmp.Diff(tc.wantArgs, gotArgs); diff != "" {
+			t.Errorf("[%q]: Load() returned unexpected args diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+func TestLoadRateLimit(t *testing.T) {
+	testData := []struct {
+		desc    string
+		content string
+		wantRes RateLimit
+		wantErr bool
+	}{
+		{
+			desc:    "limits",
+			content: "rate_limit:\n  global: {rate: 100, burst: 200}\n  default: {rate: 5, burst: 10}\n  methods:\n    AddTodo: {rate: 1, burst: 2}\n  max_streams: 3\n",
+			wantRes: RateLimit{
+				Global:     Limit{Rate: 100, Burst: 200},
+				Default:    Limit{Rate: 5, Burst: 10},
+				Methods:    map[string]Limit{"AddTodo": {Rate: 1, Burst: 2}},
+				MaxStreams: 3,
+			},
+			wantErr: false,
+		},
+		{
+			desc:    "rate without burst",
+			content: "rate_limit:\n  default: {rate: 5}\n",
+			wantErr: true,
+		},
+		{
+			desc:    "negative method rate",
+			content: "rate_limit:\n  methods:\n    AddTodo: {rate: -1, burst: 1}\n",
+			wantErr: true,
+		},
+		{
+			desc:    "negative max streams",
+			content: "rate_limit:\n  max_streams: -1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testData {
+		fs := flag.NewFlagSet("server", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+
+		got, _, err := load(fs, []string{"-config", writeFile(t, tc.content)}, func(string) (string, bool) { return "", false })
+
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("[%q]: Load() got success, want an error", tc.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%q]: Load() got error %v, want success", tc.desc, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.wantRes, got.RateLimit); diff != "" {
+			t.Errorf("[%q]: Load() returned unexpected rate limit diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+func TestLoadUnknownFileKey(t *testing.T) {
+	file := writeFile(t, "listen_adress: \":9100\"\n")
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+
+	_, _, err := load(fs, []string{"-config", file}, func(string) (string, bool) { return "", false })
+
+	if err == nil {
+		t.Errorf("Load() with misspelled key got success, want an error")
+	}
+}
@@ -1,81 +1,262 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"time"
 	"todo-app/models"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
+//todoColumns in the order scanned by extractTodos
+const todoColumns = "TodoID, UserID, Todo, Completed, CompletedAt, DueDate, Priority, CreatedAt, UpdatedAt"
+
 type Database struct {
 	db *sql.DB
+	//dialect selects the embedded migrations directory
+	dialect string
 }
 
-func GetDB(dbName string) (*Database, error) {
-	db, err := sql.Open("mysql", "root:pass123@tcp(127.0.0.1:3306)/"+dbName)
+//GetDB opens the MySQL database of the data source name
+//e.g. root:pass123@tcp(127.0.0.1:3306)/testdb
+func GetDB(dsn string) (*Database, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	//clientFoundRows makes rows affected count matched rows, see checkAffected
+	cfg.ClientFoundRows = true
+	cfg.ParseTime = true
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &Database{db: db}, nil
+	return &Database{db: db, dialect: "mysql"}, nil
 }
 
-func (this *Database) InsertTodoItem(item *models.TodoItem) (int32, error) {
-	const query = "INSERT INTO todos (UserID, Todo) VALUES(?, ?);"
-	result, err := this.db.Exec(query, item.UserID, item.Todo)
+//InsertTodoItem inserts the item and sets its CreatedAt and UpdatedAt
+func (this *Database) InsertTodoItem(ctx context.Context, item *models.TodoItem) (int32, error) {
+	const query = "INSERT INTO todos (UserID, Todo, Completed, CompletedAt, DueDate, Priority, CreatedAt, UpdatedAt) VALUES(?, ?, ?, ?, ?, ?, ?, ?);"
+	now := time.Now().UTC()
+	result, err := this.db.ExecContext(ctx, query, item.UserID, item.Todo, item.Completed, item.CompletedAt, item.DueDate, item.Priority, now, now)
 
 	if err != nil {
-		return 0, err
+		return 0, wrapError(err)
 	}
+	item.CreatedAt = now
+	item.UpdatedAt = now
 	id, err := result.LastInsertId()
-	return int32(id), err
+	return int32(id), wrapError(err)
 }
 
 func extractTodos(rows *sql.Rows) ([]*models.TodoItem, error) {
 	defer rows.Close()
 	todos := make([]*models.TodoItem, 0)
 	for rows.Next() {
-		item := &models.TodoItem{}
-		err := rows.Scan(&item.TodoID, &item.UserID, &item.Todo)
+		item, err := scanTodo(rows)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 		todos = append(todos, item)
 	}
-	return todos, nil
+	return todos, wrapError(rows.Err())
+}
+
+//scanTodo scans one row selected with todoColumns
+func scanTodo(row interface{ Scan(...interface{}) error }) (*models.TodoItem, error) {
+	item := &models.TodoItem{}
+	var completedAt, dueDate sql.NullTime
+	err := row.Scan(&item.TodoID, &item.UserID, &item.Todo, &item.Completed, &completedAt, &dueDate, &item.Priority, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if completedAt.Valid {
+		item.CompletedAt = &completedAt.Time
+	}
+	if dueDate.Valid {
+		item.DueDate = &dueDate.Time
+	}
+	return item, nil
 }
 
-func (this *Database) GetAllTodos() ([]*models.TodoItem, error) {
-	const query = "SELECT * FROM todos"
-	rows, err := this.db.Query(query)
+func (this *Database) GetAllTodos(ctx context.Context) ([]*models.TodoItem, error) {
+	const query = "SELECT " + todoColumns + " FROM todos"
+	rows, err := this.db.QueryContext(ctx, query)
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return extractTodos(rows)
 }
 
-func (this *Database) GetUserTodos(userID int32) ([]*models.TodoItem, error) {
-	const query = "SELECT * FROM todos WHERE UserID = ?"
-	rows, err := this.db.Query(query, userID)
+func (this *Database) GetUserTodos(ctx context.Context, userID int32) ([]*models.TodoItem, error) {
+	const query = "SELECT " + todoColumns + " FROM todos WHERE UserID = ?"
+	rows, err := this.db.QueryContext(ctx, query, userID)
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
+	}
+
+	return extractTodos(rows)
+}
+
+//GetAllTodosPage returns up to limit todos with id greater than afterID ordered by id
+func (this *Database) GetAllTodosPage(ctx context.Context, afterID int32, limit int) ([]*models.TodoItem, error) {
+	const query = "SELECT " + todoColumns + " FROM todos WHERE TodoID > ? ORDER BY TodoID LIMIT ?"
+	rows, err := this.db.QueryContext(ctx, query, afterID, limit)
+
+	if err != nil {
+		return nil, wrapError(err)
 	}
 
 	return extractTodos(rows)
 }
 
-func (this *Database) DeleteUserTodos(userID int32) error {
+//GetUserTodosPage returns up to limit user todos with id greater than afterID ordered by id
+func (this *Database) GetUserTodosPage(ctx context.Context, userID int32, afterID int32, limit int) ([]*models.TodoItem, error) {
+	const query = "SELECT " + todoColumns + " FROM todos WHERE UserID = ? AND TodoID > ? ORDER BY TodoID LIMIT ?"
+	rows, err := this.db.QueryContext(ctx, query, userID, afterID, limit)
+
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return extractTodos(rows)
+}
+
+func (this *Database) GetTodoItem(ctx context.Context, todoID int32) (*models.TodoItem, error) {
+	const query = "SELECT " + todoColumns + " FROM todos WHERE TodoID = ?"
+	item, err := scanTodo(this.db.QueryRowContext(ctx, query, todoID))
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	return item, wrapError(err)
+}
+
+func (this *Database) DeleteUserTodos(ctx context.Context, userID int32) error {
 	const query = "DELETE FROM todos WHERE UserID = ?"
-	_, err := this.db.Exec(query, userID)
-	return err
+	_, err := this.db.ExecContext(ctx, query, userID)
+	return wrapError(err)
+}
+
+//UpdateTodoItem updates the text of the todo item with the same id
+func (this *Database) UpdateTodoItem(ctx context.Context, item *models.TodoItem) error {
+	const query = "UPDATE todos SET Todo = ?, UpdatedAt = ? WHERE TodoID = ?"
+	result, err := this.db.ExecContext(ctx, query, item.Todo, time.Now().UTC(), item.TodoID)
+
+	if err != nil {
+		return wrapError(err)
+	}
+
+	return checkAffected(result)
+}
+
+//SetTodoCompleted marks the todo item as completed now, or clears its completion
+func (this *Database) SetTodoCompleted(ctx context.Context, todoID int32, completed bool) error {
+	const query = "UPDATE todos SET Completed = ?, CompletedAt = ?, UpdatedAt = ? WHERE TodoID = ?"
+	now := time.Now().UTC()
+	var completedAt *time.Time
+	if completed {
+		completedAt = &now
+	}
+	result, err := this.db.ExecContext(ctx, query, completed, completedAt, now, todoID)
+
+	if err != nil {
+		return wrapError(err)
+	}
+
+	return checkAffected(result)
+}
+
+//SetTodoDueDate sets the due date of the todo item, nil clears it
+func (this *Database) SetTodoDueDate(ctx context.Context, todoID int32, dueDate *time.Time) error {
+	const query = "UPDATE todos SET DueDate = ?, UpdatedAt = ? WHERE TodoID = ?"
+	result, err := this.db.ExecContext(ctx, query, dueDate, time.Now().UTC(), todoID)
+
+	if err != nil {
+		return wrapError(err)
+	}
+
+	return checkAffected(result)
+}
+
+//SetTodoPriority sets the priority of the todo item
+func (this *Database) SetTodoPriority(ctx context.Context, todoID int32, priority models.Priority) error {
+	const query = "UPDATE todos SET Priority = ?, UpdatedAt = ? WHERE TodoID = ?"
+	result, err := this.db.ExecContext(ctx, query, priority, time.Now().UTC(), todoID)
+
+	if err != nil {
+		return wrapError(err)
+	}
+
+	return checkAffected(result)
 }
 
-func (this *Database) Truncate() error {
+func (this *Database) DeleteTodoItem(ctx context.Context, todoID int32) error {
+	const query = "DELETE FROM todos WHERE TodoID = ?"
+	result, err := this.db.ExecContext(ctx, query, todoID)
+
+	if err != nil {
+		return wrapError(err)
+	}
+
+	return checkAffected(result)
+}
+
+//GetUserTodosVersion returns the version of the todos of the user, the triggers of the todos table change it with every write
+//users without a row never had todos since the table was created
+func (this *Database) GetUserTodosVersion(ctx context.Context, userID int32) (int64, error) {
+	const query = "SELECT Version FROM todo_versions WHERE UserID = ?"
+	var version int64
+	err := this.db.QueryRowContext(ctx, query, userID).Scan(&version)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, wrapError(err)
+}
+
+//checkAffected returns models.ErrNotFound when no row matched the statement
+//GetDB sets clientFoundRows so MySQL counts matched rows even when nothing changed
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+	if affected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+//Ping connects to the database if needed, sql.Open doesn't connect
+func (this *Database) Ping(ctx context.Context) error {
+	return wrapError(this.db.PingContext(ctx))
+}
+
+//Close closes the connection pool
+func (this *Database) Close() error {
+	return this.db.Close()
+}
+
+//DB returns the connection pool, e.g. to export its statistics
+func (this *Database) DB() *sql.DB {
+	return this.db
+}
+
+//Truncate deletes all todos, TRUNCATE doesn't fire the triggers so the versions of all users are changed after it
+func (this *Database) Truncate(ctx context.Context) error {
 	const query = "TRUNCATE TABLE todos;"
-	_, err := this.db.Exec(query)
-	return err
+	if _, err := this.db.ExecContext(ctx, query); err != nil {
+		return wrapError(err)
+	}
+	const versions = "UPDATE todo_versions SET Version = GREATEST(Version + 1, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED))"
+	_, err := this.db.ExecContext(ctx, versions)
+	return wrapError(err)
 }
@@ -1,9 +1,11 @@
 package db
 
 import (
+	"context"
 	"log"
 	"os"
 	"testing"
+	"time"
 	"todo-app/models"
 
 	"github.com/google/go-cmp/cmp"
@@ -12,25 +14,34 @@ import (
 
 var database *Database
 
-const dbName = "TodoTestDB"
+const dsn = "root:pass123@tcp(127.0.0.1:3306)/TodoTestDB"
+
+//ignoreTimestamps ignores the timestamps set by the database on insert and update
+var ignoreTimestamps = cmpopts.IgnoreFields(models.TodoItem{}, "CreatedAt", "UpdatedAt")
+
+//testingDueDate has no sub-microsecond part so it survives DATETIME(6)
+var testingDueDate = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
 
 func TestMain(m *testing.M) {
-	db, err := GetDB(dbName)
+	db, err := GetDB(dsn)
 	defer db.db.Close()
 	if err != nil {
 		log.Fatalf("cannot connect to database")
 	}
+	if _, err := db.MigrateUp(); err != nil {
+		log.Printf("cannot migrate database %v", err)
+	}
 	database = db
 	os.Exit(m.Run())
 }
 
 func setup(t *testing.T, initialTodos []*models.TodoItem) {
-	err := database.Truncate()
+	err := database.Truncate(context.Background())
 	if err != nil {
 		t.Errorf("Error in setup database %v", err)
 	}
 	for _, todo := range initialTodos {
-		_, err := database.InsertTodoItem(todo)
+		_, err := database.InsertTodoItem(context.Background(), todo)
 		if err != nil {
 			t.Errorf("Error in setup database %v", err)
 		}
@@ -119,7 +130,7 @@ func TestInsertTodoItem(t *testing.T) {
 
 		for _, todo := range tc.input {
 
-			id, err := database.InsertTodoItem(todo)
+			id, err := database.InsertTodoItem(context.Background(), todo)
 
 			if err != nil {
 				gotError = true
@@ -197,7 +208,7 @@ func TestDeleteUserTodos(t *testing.T) {
 
 		setup(t, tc.env)
 
-		err := database.DeleteUserTodos(tc.input)
+		err := database.DeleteUserTodos(context.Background(), tc.input)
 
 		if tc.wantErr {
 			if err == nil {
@@ -210,7 +221,7 @@ func TestDeleteUserTodos(t *testing.T) {
 			t.Errorf("[%q]: DeleteUserTodos() got error %v, want success", tc.desc, err)
 		}
 
-		todos, err := database.GetUserTodos(tc.input)
+		todos, err := database.GetUserTodos(context.Background(), tc.input)
 
 		//Error in get user todos
 		if err != nil {
@@ -283,7 +294,7 @@ func TestGetUserTodos(t *testing.T) {
 
 		setup(t, tc.env)
 
-		got, err := database.GetUserTodos(tc.input)
+		got, err := database.GetUserTodos(context.Background(), tc.input)
 
 		if tc.wantErr {
 			if err == nil {
@@ -296,7 +307,7 @@ func TestGetUserTodos(t *testing.T) {
 			t.Errorf("[%q]: GetUserTodos() got error %v, want success", tc.desc, err)
 		}
 
-		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{})); diff != "" {
+		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{}), ignoreTimestamps); diff != "" {
 			t.Errorf("[%q]: InsertTodoItem() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
 		}
 	}
@@ -353,7 +364,7 @@ func TestGetAllTodos(t *testing.T) {
 
 		setup(t, tc.env)
 
-		got, err := database.GetAllTodos()
+		got, err := database.GetAllTodos(context.Background())
 
 		if tc.wantErr {
 			if err == nil {
@@ -366,8 +377,385 @@ func TestGetAllTodos(t *testing.T) {
 			t.Errorf("[%q]: GetUserTodos() got error %v, want success", tc.desc, err)
 		}
 
-		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{})); diff != "" {
+		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{}), ignoreTimestamps); diff != "" {
 			t.Errorf("[%q]: GetUserTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
 		}
 	}
 }
+
+//TestUpdateTodoItem checks updating the text of one todo item
+func TestUpdateTodoItem(t *testing.T) {
+
+	testData := []struct {
+		desc    string
+		env     []*models.TodoItem
+		input   *models.TodoItem
+		wantRes []*models.TodoItem
+		wantErr error
+	}{
+		{
+			desc: "update existing todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+			},
+			input: &models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1 fixed"},
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1 fixed"},
+			},
+			wantErr: nil,
+		},
+		{
+			desc: "update with unchanged text",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+			},
+			input: &models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantErr: nil,
+		},
+		{
+			desc: "update keeps due date and priority",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1", DueDate: &testingDueDate, Priority: models.PriorityHigh},
+			},
+			input: &models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1 fixed"},
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1 fixed", DueDate: &testingDueDate, Priority: models.PriorityHigh},
+			},
+			wantErr: nil,
+		},
+		{
+			desc: "update missing todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+			},
+			input: &models.TodoItem{TodoID: 5, UserID: 1, Todo: "Task 5"},
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantErr: models.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testData {
+
+		setup(t, tc.env)
+
+		err := database.UpdateTodoItem(context.Background(), tc.input)
+
+		if err != tc.wantErr {
+			t.Errorf("[%q]: UpdateTodoItem() got error %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+
+		got, err := database.GetAllTodos(context.Background())
+
+		if err != nil {
+			t.Errorf("[%q]: error in getting all todos (external function)", tc.desc)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{}), ignoreTimestamps); diff != "" {
+			t.Errorf("[%q]: UpdateTodoItem() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+//TestDeleteTodoItem checks deleting one todo item
+func TestDeleteTodoItem(t *testing.T) {
+
+	testData := []struct {
+		desc    string
+		env     []*models.TodoItem
+		input   int32
+		wantRes []*models.TodoItem
+		wantErr error
+	}{
+		{
+			desc: "delete existing todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+			},
+			input: 2,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+			},
+			wantErr: nil,
+		},
+		{
+			desc: "delete missing todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+			},
+			input: 5,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantErr: models.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testData {
+
+		setup(t, tc.env)
+
+		err := database.DeleteTodoItem(context.Background(), tc.input)
+
+		if err != tc.wantErr {
+			t.Errorf("[%q]: DeleteTodoItem() got error %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+
+		got, err := database.GetAllTodos(context.Background())
+
+		if err != nil {
+			t.Errorf("[%q]: error in getting all todos (external function)", tc.desc)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{}), ignoreTimestamps); diff != "" {
+			t.Errorf("[%q]: DeleteTodoItem() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+//TestGetTodoItem checks getting one todo item by id
+func TestGetTodoItem(t *testing.T) {
+
+	testData := []struct {
+		desc    string
+		env     []*models.TodoItem
+		input   int32
+		wantRes *models.TodoItem
+		wantErr error
+	}{
+		{
+			desc: "existing todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1", Priority: models.PriorityLow},
+			},
+			input:   2,
+			wantRes: &models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1", Priority: models.PriorityLow},
+			wantErr: nil,
+		},
+		{
+			desc: "missing todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+			},
+			input:   3,
+			wantRes: nil,
+			wantErr: models.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testData {
+
+		setup(t, tc.env)
+
+		got, err := database.GetTodoItem(context.Background(), tc.input)
+
+		if err != tc.wantErr {
+			t.Errorf("[%q]: GetTodoItem() got error %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{}), ignoreTimestamps); diff != "" {
+			t.Errorf("[%q]: GetTodoItem() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+//TestSetTodoCompleted checks completing and reopening a todo item
+func TestSetTodoCompleted(t *testing.T) {
+
+	testData := []struct {
+		desc    string
+		env     []*models.TodoItem
+		todoID  int32
+		input   bool
+		wantErr error
+	}{
+		{
+			desc: "complete todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+			},
+			todoID:  1,
+			input:   true,
+			wantErr: nil,
+		},
+		{
+			desc: "reopen todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1", Completed: true},
+			},
+			todoID:  1,
+			input:   false,
+			wantErr: nil,
+		},
+		{
+			desc: "missing todo",
+			env: []*models.TodoItem{
+				&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+			},
+			todoID:  2,
+			input:   true,
+			wantErr: models.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testData {
+
+		setup(t, tc.env)
+
+		err := database.SetTodoCompleted(context.Background(), tc.todoID, tc.input)
+
+		if err != tc.wantErr {
+			t.Errorf("[%q]: SetTodoCompleted() got error %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		got, err := database.GetTodoItem(context.Background(), tc.todoID)
+
+		if err != nil {
+			t.Errorf("[%q]: error in getting todo item (external function)", tc.desc)
+			continue
+		}
+
+		if got.Completed != tc.input || (got.CompletedAt != nil) != tc.input {
+			t.Errorf("[%q]: SetTodoCompleted() got completed %v at %v, want completed %v", tc.desc, got.Completed, got.CompletedAt, tc.input)
+		}
+	}
+}
+
+//TestGetAllTodosPage checks paging through all todos by id
+func TestGetAllTodosPage(t *testing.T) {
+
+	env := []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2"},
+	}
+
+	testData := []struct {
+		desc    string
+		afterID int32
+		limit   int
+		wantRes []*models.TodoItem
+	}{
+		{
+			desc:    "first page",
+			afterID: 0,
+			limit:   2,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+			},
+		},
+		{
+			desc:    "last page",
+			afterID: 2,
+			limit:   2,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+			},
+		},
+		{
+			desc:    "past the end",
+			afterID: 3,
+			limit:   2,
+			wantRes: []*models.TodoItem{},
+		},
+	}
+
+	for _, tc := range testData {
+
+		setup(t, env)
+
+		got, err := database.GetAllTodosPage(context.Background(), tc.afterID, tc.limit)
+
+		if err != nil {
+			t.Errorf("[%q]: GetAllTodosPage() got error %v, want success", tc.desc, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{}), ignoreTimestamps); diff != "" {
+			t.Errorf("[%q]: GetAllTodosPage() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+//TestGetUserTodosPage checks paging through user todos by id
+func TestGetUserTodosPage(t *testing.T) {
+
+	env := []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 3"},
+	}
+
+	testData := []struct {
+		desc    string
+		userID  int32
+		afterID int32
+		limit   int
+		wantRes []*models.TodoItem
+	}{
+		{
+			desc:    "first page",
+			userID:  1,
+			afterID: 0,
+			limit:   2,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+			},
+		},
+		{
+			desc:    "last page",
+			userID:  1,
+			afterID: 3,
+			limit:   2,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 4, UserID: 1, Todo: "Task 3"},
+			},
+		},
+		{
+			desc:    "no user todos",
+			userID:  3,
+			afterID: 0,
+			limit:   2,
+			wantRes: []*models.TodoItem{},
+		},
+	}
+
+	for _, tc := range testData {
+
+		setup(t, env)
+
+		got, err := database.GetUserTodosPage(context.Background(), tc.userID, tc.afterID, tc.limit)
+
+		if err != nil {
+			t.Errorf("[%q]: GetUserTodosPage() got error %v, want success", tc.desc, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, cmpopts.IgnoreUnexported(models.TodoItem{}), ignoreTimestamps); diff != "" {
+			t.Errorf("[%q]: GetUserTodosPage() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"todo-app/models"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+//MySQL server error numbers of rejected column values
+const (
+	erTruncatedWrongValue      = 1292
+	erTruncatedWrongValueField = 1366
+	erDataTooLong              = 1406
+)
+
+//columnName extracts the column of MySQL errors like "Data too long for column 'Todo' at row 1"
+var columnName = regexp.MustCompile(`column '(\w+)'`)
+
+//wrapError classifies driver errors as models errors so callers don't depend on the driver
+//errors without a matching models error are returned unchanged
+func wrapError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %v", models.ErrUnavailable, err)
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case erTruncatedWrongValue, erTruncatedWrongValueField, erDataTooLong:
+			field := "item"
+			if match := columnName.FindStringSubmatch(mysqlErr.Message); match != nil {
+				field = match[1]
+			}
+			return &models.FieldError{Field: field, Description: mysqlErr.Message}
+		}
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+	"todo-app/models"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrapError(t *testing.T) {
+	sqlErr := errors.New("syntax error")
+	testData := []struct {
+		desc      string
+		input     error
+		wantIs    error
+		wantField *models.FieldError
+	}{
+		{desc: "nil", input: nil, wantIs: nil},
+		{desc: "deadline", input: context.DeadlineExceeded, wantIs: context.DeadlineExceeded},
+		{desc: "bad connection", input: driver.ErrBadConn, wantIs: models.ErrUnavailable},
+		{desc: "connection refused", input: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, wantIs: models.ErrUnavailable},
+		{desc: "not found", input: models.ErrNotFound, wantIs: models.ErrNotFound},
+		{desc: "other", input: sqlErr, wantIs: sqlErr},
+		{
+			desc:      "data too long",
+			input:     &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'Todo' at row 1"},
+			wantField: &models.FieldError{Field: "Todo", Description: "Data too long for column 'Todo' at row 1"},
+		},
+	}
+
+	for _, tc := range testData {
+		got := wrapError(tc.input)
+
+		if tc.wantField != nil {
+			var fieldErr *models.FieldError
+			if !errors.As(got, &fieldErr) {
+				t.Errorf("[%q]: wrapError() got %v, want a field error", tc.desc, got)
+				continue
+			}
+			if diff := cmp.Diff(tc.wantField, fieldErr); diff != "" {
+				t.Errorf("[%q]: wrapError() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			}
+			continue
+		}
+
+		if !errors.Is(got, tc.wantIs) {
+			t.Errorf("[%q]: wrapError() got %v, want %v", tc.desc, got, tc.wantIs)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+//Package migrate applies versioned SQL migrations embedded in the binary
+//and records the applied versions in a schema_version table
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//fileName matches migration files named <version>_<name>.up.sql or <version>_<name>.down.sql
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+//ErrNoDown returned when rolling back a migration without a down file
+var ErrNoDown = errors.New("migration has no down file")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	//Down is empty when the migration can't be rolled back
+	Down string
+}
+
+//State of a migration in the database
+type State struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+//Load reads the migrations in the root of fsys sorted by version
+//every version must have an up file, the down file is optional
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %v", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %d_%s and %s have the same version", version, migration.Name, entry.Name())
+		}
+		text := &migration.Up
+		if match[3] == "down" {
+			text = &migration.Down
+		}
+		if *text != "" {
+			return nil, fmt.Errorf("migration %s is duplicated", entry.Name())
+		}
+		*text = string(content)
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+//triggerBody matches a statement starting a trigger whose body is a BEGIN ... END block
+var triggerBody = regexp.MustCompile(`(?ism)^\s*CREATE\s+TRIGGER\b.*\bBEGIN\b`)
+
+//statements splits a migration into statements ending with a semicolon
+//the statements in the body of a trigger stay in the trigger up to its END
+func statements(migration string) []string {
+	var res []string
+	var trigger []string
+	for _, statement := range strings.Split(migration, ";") {
+		statement = strings.TrimSpace(statement)
+		switch {
+		case statement == "":
+		case trigger != nil:
+			trigger = append(trigger, statement)
+			if strings.EqualFold(statement, "END") {
+				res = append(res, strings.Join(trigger, ";\n"))
+				trigger = nil
+			}
+		case triggerBody.MatchString(statement):
+			trigger = []string{statement}
+		default:
+			res = append(res, statement)
+		}
+	}
+	//an unterminated trigger is left for the database to reject
+	if trigger != nil {
+		res = append(res, strings.Join(trigger, ";\n"))
+	}
+	return res
+}
+
+func ensureVersionTable(db *sql.DB) error {
+	const query = "CREATE TABLE IF NOT EXISTS schema_version (version INT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL)"
+	_, err := db.Exec(query)
+	return err
+}
+
+//Version returns the latest applied migration version, 0 when none were applied
+func Version(db *sql.DB) (int, error) {
+	if err := ensureVersionTable(db); err != nil {
+		return 0, err
+	}
+	const query = "SELECT COALESCE(MAX(version), 0) FROM schema_version"
+	var version int
+	err := db.QueryRow(query).Scan(&version)
+	return version, err
+}
+
+//Up applies the migrations newer than the current version in order, each in its own transaction
+//it returns the number of applied migrations
+func Up(db *sql.DB, migrations []Migration) (int, error) {
+	current, err := Version(db)
+	if err != nil {
+		return 0, err
+	}
+	applied := 0
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+		const query = "INSERT INTO schema_version (version, name) VALUES(?, ?)"
+		err := apply(db, migration.Up, query, migration.Version, migration.Name)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+//Down rolls back the latest applied migration and returns it
+//it returns nil when no migration is applied
+func Down(db *sql.DB, migrations []Migration) (*Migration, error) {
+	current, err := Version(db)
+	if err != nil || current == 0 {
+		return nil, err
+	}
+	for _, migration := range migrations {
+		if migration.Version != current {
+			continue
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, ErrNoDown)
+		}
+		const query = "DELETE FROM schema_version WHERE version = ?"
+		err := apply(db, migration.Down, query, migration.Version)
+		if err != nil {
+			return nil, fmt.Errorf("migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+	return nil, fmt.Errorf("applied migration %d is unknown to this binary", current)
+}
+
+//Status returns the state of every migration and of applied versions missing from migrations
+func Status(db *sql.DB, migrations []Migration) ([]State, error) {
+	if err := ensureVersionTable(db); err != nil {
+		return nil, err
+	}
+	const query = "SELECT version, name FROM schema_version"
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var name string
+		if err := rows.Scan(&version, &name); err != nil {
+			return nil, err
+		}
+		applied[version] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	states := make([]State, 0, len(migrations))
+	for _, migration := range migrations {
+		_, ok := applied[migration.Version]
+		delete(applied, migration.Version)
+		states = append(states, State{Version: migration.Version, Name: migration.Name, Applied: ok})
+	}
+	for version, name := range applied {
+		states = append(states, State{Version: version, Name: name, Applied: true})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Version < states[j].Version
+	})
+	return states, nil
+}
+
+//apply runs the statements of a migration and the schema_version query in one transaction
+//MySQL commits DDL statements implicitly so a failed migration may be partially applied there
+func apply(db *sql.DB, migration string, query string, args ...interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements(migration) {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,101 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	testData := []struct {
+		desc    string
+		input   fstest.MapFS
+		wantRes []Migration
+		wantErr bool
+	}{
+		{
+			desc: "sorted by version",
+			input: fstest.MapFS{
+				"0002_add_index.up.sql":      &fstest.MapFile{Data: []byte("CREATE INDEX b;")},
+				"0001_create_todos.up.sql":   &fstest.MapFile{Data: []byte("CREATE TABLE a;")},
+				"0001_create_todos.down.sql": &fstest.MapFile{Data: []byte("DROP TABLE a;")},
+				"README.md":                  &fstest.MapFile{Data: []byte("ignored")},
+			},
+			wantRes: []Migration{
+				{Version: 1, Name: "create_todos", Up: "CREATE TABLE a;", Down: "DROP TABLE a;"},
+				{Version: 2, Name: "add_index", Up: "CREATE INDEX b;"},
+			},
+			wantErr: false,
+		},
+		{
+			desc: "duplicate version",
+			input: fstest.MapFS{
+				"0001_create_todos.up.sql": &fstest.MapFile{Data: []byte("CREATE TABLE a;")},
+				"1_create_users.up.sql":    &fstest.MapFile{Data: []byte("CREATE TABLE b;")},
+			},
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			desc: "down without up",
+			input: fstest.MapFS{
+				"0001_create_todos.down.sql": &fstest.MapFile{Data: []byte("DROP TABLE a;")},
+			},
+			wantRes: nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testData {
+		got, err := Load(tc.input)
+
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("[%q]: Load() got success, want an error", tc.desc)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("[%q]: Load() got error %v, want success", tc.desc, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got); diff != "" {
+			t.Errorf("[%q]: Load() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	testData := []struct {
+		desc    string
+		input   string
+		wantRes []string
+	}{
+		{
+			desc:    "statements",
+			input:   "CREATE TABLE a (id INT);\n\nCREATE INDEX b ON a (id);\n",
+			wantRes: []string{"CREATE TABLE a (id INT)", "CREATE INDEX b ON a (id)"},
+		},
+		{
+			desc:    "trigger body",
+			input:   "CREATE TABLE a (id INT);\n-- counts\nCREATE TRIGGER t AFTER INSERT ON a BEGIN\n    UPDATE b SET n = n + 1;\n    UPDATE c SET n = n + 1;\nEND;\nCREATE INDEX b ON a (id);",
+			wantRes: []string{"CREATE TABLE a (id INT)", "-- counts\nCREATE TRIGGER t AFTER INSERT ON a BEGIN\n    UPDATE b SET n = n + 1;\nUPDATE c SET n = n + 1;\nEND", "CREATE INDEX b ON a (id)"},
+		},
+		{
+			desc:    "trigger without body",
+			input:   "CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW UPDATE b SET n = n + 1;\nCREATE INDEX b ON a (id);",
+			wantRes: []string{"CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW UPDATE b SET n = n + 1", "CREATE INDEX b ON a (id)"},
+		},
+	}
+
+	for _, tc := range testData {
+		got := statements(tc.input)
+
+		if diff := cmp.Diff(tc.wantRes, got); diff != "" {
+			t.Errorf("[%q]: statements() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
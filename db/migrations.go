@@ -0,0 +1,46 @@
+package db
+
+import (
+	"embed"
+	"io/fs"
+	"todo-app/db/migrate"
+)
+
+//go:embed migrations/mysql/*.sql migrations/sqlite/*.sql
+var migrations embed.FS
+
+//loadMigrations loads the migrations embedded for the given dialect directory
+func loadMigrations(dialect string) ([]migrate.Migration, error) {
+	dir, err := fs.Sub(migrations, "migrations/"+dialect)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.Load(dir)
+}
+
+//MigrateUp applies pending schema migrations and returns how many were applied
+func (this *Database) MigrateUp() (int, error) {
+	list, err := loadMigrations(this.dialect)
+	if err != nil {
+		return 0, err
+	}
+	return migrate.Up(this.db, list)
+}
+
+//MigrateDown rolls back the latest applied schema migration, it returns nil when none is applied
+func (this *Database) MigrateDown() (*migrate.Migration, error) {
+	list, err := loadMigrations(this.dialect)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.Down(this.db, list)
+}
+
+//MigrationStatus returns whether each schema migration is applied
+func (this *Database) MigrationStatus() ([]migrate.State, error) {
+	list, err := loadMigrations(this.dialect)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.Status(this.db, list)
+}
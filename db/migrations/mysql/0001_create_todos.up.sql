@@ -0,0 +1,7 @@
+-- IF NOT EXISTS adopts todos tables created by hand before migrations existed
+CREATE TABLE IF NOT EXISTS todos (
+    TodoID INT NOT NULL AUTO_INCREMENT,
+    UserID INT NOT NULL,
+    Todo TEXT NOT NULL,
+    PRIMARY KEY (TodoID)
+);
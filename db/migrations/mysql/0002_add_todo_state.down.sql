@@ -0,0 +1,7 @@
+ALTER TABLE todos
+    DROP COLUMN Completed,
+    DROP COLUMN CompletedAt,
+    DROP COLUMN DueDate,
+    DROP COLUMN Priority,
+    DROP COLUMN CreatedAt,
+    DROP COLUMN UpdatedAt;
@@ -0,0 +1,7 @@
+ALTER TABLE todos
+    ADD COLUMN Completed BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN CompletedAt DATETIME(6) NULL,
+    ADD COLUMN DueDate DATETIME(6) NULL,
+    ADD COLUMN Priority INT NOT NULL DEFAULT 0,
+    ADD COLUMN CreatedAt DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    ADD COLUMN UpdatedAt DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
@@ -0,0 +1 @@
+DROP INDEX idx_todos_user ON todos;
@@ -0,0 +1 @@
+CREATE INDEX idx_todos_user ON todos (UserID, TodoID);
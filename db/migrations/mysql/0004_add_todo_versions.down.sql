@@ -0,0 +1,4 @@
+DROP TRIGGER todos_delete_version;
+DROP TRIGGER todos_update_version;
+DROP TRIGGER todos_insert_version;
+DROP TABLE todo_versions;
@@ -0,0 +1,20 @@
+-- todo_versions counts the writes to the todos of each user, the triggers update it in the same statement as the write
+-- a write sets the version to the time in microseconds unless that's not greater than the version plus one,
+-- so versions don't repeat after the table is recreated or restored
+CREATE TABLE todo_versions (
+    UserID INT NOT NULL,
+    Version BIGINT NOT NULL,
+    PRIMARY KEY (UserID)
+);
+INSERT INTO todo_versions (UserID, Version) SELECT DISTINCT UserID, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED) FROM todos;
+CREATE TRIGGER todos_insert_version AFTER INSERT ON todos FOR EACH ROW
+    INSERT INTO todo_versions (UserID, Version) VALUES (NEW.UserID, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED))
+    ON DUPLICATE KEY UPDATE Version = GREATEST(Version + 1, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED));
+CREATE TRIGGER todos_update_version AFTER UPDATE ON todos FOR EACH ROW BEGIN
+    INSERT INTO todo_versions (UserID, Version) VALUES (OLD.UserID, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED))
+    ON DUPLICATE KEY UPDATE Version = GREATEST(Version + 1, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED));
+    INSERT INTO todo_versions (UserID, Version) VALUES (NEW.UserID, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED))
+    ON DUPLICATE KEY UPDATE Version = GREATEST(Version + 1, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED));
+END;
+CREATE TRIGGER todos_delete_version AFTER DELETE ON todos FOR EACH ROW
+    UPDATE todo_versions SET Version = GREATEST(Version + 1, CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)) WHERE UserID = OLD.UserID;
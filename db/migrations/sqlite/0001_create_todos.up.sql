@@ -0,0 +1,13 @@
+CREATE TABLE todos (
+    TodoID INTEGER PRIMARY KEY AUTOINCREMENT,
+    UserID INTEGER NOT NULL,
+    Todo TEXT NOT NULL,
+    Completed BOOLEAN NOT NULL DEFAULT 0,
+    CompletedAt DATETIME NULL,
+    DueDate DATETIME NULL,
+    Priority INTEGER NOT NULL DEFAULT 0,
+    CreatedAt DATETIME NOT NULL,
+    UpdatedAt DATETIME NOT NULL
+);
+
+CREATE INDEX idx_todos_user ON todos (UserID, TodoID);
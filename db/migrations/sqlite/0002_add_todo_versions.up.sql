@@ -0,0 +1,25 @@
+-- todo_versions counts the writes to the todos of each user, the triggers update it in the same statement as the write
+-- a write sets the version to the time in microseconds unless that's not greater than the version plus one,
+-- so versions don't repeat after the table is recreated or restored
+CREATE TABLE todo_versions (
+    UserID INTEGER PRIMARY KEY,
+    Version INTEGER NOT NULL
+);
+
+INSERT INTO todo_versions (UserID, Version) SELECT DISTINCT UserID, CAST((julianday('now') - 2440587.5) * 86400000000 AS INTEGER) FROM todos;
+
+CREATE TRIGGER todos_insert_version AFTER INSERT ON todos BEGIN
+    INSERT INTO todo_versions (UserID, Version) VALUES (NEW.UserID, CAST((julianday('now') - 2440587.5) * 86400000000 AS INTEGER))
+        ON CONFLICT (UserID) DO UPDATE SET Version = MAX(Version + 1, excluded.Version);
+END;
+
+CREATE TRIGGER todos_update_version AFTER UPDATE ON todos BEGIN
+    INSERT INTO todo_versions (UserID, Version) VALUES (OLD.UserID, CAST((julianday('now') - 2440587.5) * 86400000000 AS INTEGER))
+        ON CONFLICT (UserID) DO UPDATE SET Version = MAX(Version + 1, excluded.Version);
+    INSERT INTO todo_versions (UserID, Version) VALUES (NEW.UserID, CAST((julianday('now') - 2440587.5) * 86400000000 AS INTEGER))
+        ON CONFLICT (UserID) DO UPDATE SET Version = MAX(Version + 1, excluded.Version);
+END;
+
+CREATE TRIGGER todos_delete_version AFTER DELETE ON todos BEGIN
+    UPDATE todo_versions SET Version = MAX(Version + 1, CAST((julianday('now') - 2440587.5) * 86400000000 AS INTEGER)) WHERE UserID = OLD.UserID;
+END;
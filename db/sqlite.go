@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	_ "modernc.org/sqlite"
+)
+
+//SQLiteDatabase stores todos in a SQLite file using the same queries as Database
+type SQLiteDatabase struct {
+	Database
+}
+
+//GetSQLiteDB opens the SQLite database at path, creating it if needed, and applies pending migrations
+func GetSQLiteDB(path string) (*SQLiteDatabase, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+
+	if err != nil {
+		return nil, err
+	}
+
+	//SQLite allows a single writer, one connection also keeps a :memory: database shared
+	db.SetMaxOpenConns(1)
+
+	database := &SQLiteDatabase{Database{db: db, dialect: "sqlite"}}
+	if _, err := database.MigrateUp(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return database, nil
+}
+
+//Truncate deletes all todos and restarts ids from 1, SQLite has no TRUNCATE TABLE
+func (this *SQLiteDatabase) Truncate(ctx context.Context) error {
+	tx, err := this.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM todos"); err != nil {
+		tx.Rollback()
+		return wrapError(err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sqlite_sequence WHERE name = 'todos'"); err != nil {
+		tx.Rollback()
+		return wrapError(err)
+	}
+	return wrapError(tx.Commit())
+}
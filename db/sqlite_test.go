@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-app/db/migrate"
+	"todo-app/models"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func openSQLite(t *testing.T) *SQLiteDatabase {
+	database, err := GetSQLiteDB(filepath.Join(t.TempDir(), "todos.db"))
+	if err != nil {
+		t.Fatalf("GetSQLiteDB() got error %v, want success", err)
+	}
+	t.Cleanup(func() { database.db.Close() })
+	return database
+}
+
+//TestGetSQLiteDBMigrations checks the schema is created once and reopening applies nothing
+func TestGetSQLiteDBMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+
+	for i := 0; i < 2; i++ {
+		database, err := GetSQLiteDB(path)
+		if err != nil {
+			t.Fatalf("GetSQLiteDB() open %d got error %v, want success", i, err)
+		}
+		version, err := migrate.Version(database.db)
+		if err != nil {
+			t.Fatalf("Version() got error %v, want success", err)
+		}
+		if version != 2 {
+			t.Errorf("Version() got %d after open %d, want 2", version, i)
+		}
+		if _, err := database.InsertTodoItem(context.Background(), &models.TodoItem{UserID: 1, Todo: "Task 1"}); err != nil {
+			t.Errorf("InsertTodoItem() got error %v, want success", err)
+		}
+		database.db.Close()
+	}
+}
+
+//TestSQLiteMigrateDown checks rolling back and re-applying the schema
+func TestSQLiteMigrateDown(t *testing.T) {
+	database := openSQLite(t)
+
+	for _, version := range []int{2, 1} {
+		migration, err := database.MigrateDown()
+		if err != nil || migration == nil || migration.Version != version {
+			t.Fatalf("MigrateDown() got %v error %v, want migration %d", migration, err, version)
+		}
+	}
+	if _, err := database.GetAllTodos(context.Background()); err == nil {
+		t.Errorf("GetAllTodos() after MigrateDown() got success, want an error")
+	}
+
+	migration, err := database.MigrateDown()
+	if err != nil || migration != nil {
+		t.Errorf("MigrateDown() with nothing applied got %v error %v, want nil", migration, err)
+	}
+
+	states, err := database.MigrationStatus()
+	want := []migrate.State{
+		{Version: 1, Name: "create_todos", Applied: false},
+		{Version: 2, Name: "add_todo_versions", Applied: false},
+	}
+	if diff := cmp.Diff(want, states); err != nil || diff != "" {
+		t.Errorf("MigrationStatus() got error %v diff (-want, +got):\n%s", err, diff)
+	}
+
+	applied, err := database.MigrateUp()
+	if err != nil || applied != 2 {
+		t.Errorf("MigrateUp() got %d error %v, want 2", applied, err)
+	}
+	if _, err := database.GetAllTodos(context.Background()); err != nil {
+		t.Errorf("GetAllTodos() after MigrateUp() got error %v, want success", err)
+	}
+}
+
+//TestSQLiteDatabase checks the shared queries against SQLite
+func TestSQLiteDatabase(t *testing.T) {
+	database := openSQLite(t)
+
+	for _, todo := range []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2", DueDate: &testingDueDate, Priority: models.PriorityHigh},
+	} {
+		if _, err := database.InsertTodoItem(context.Background(), todo); err != nil {
+			t.Fatalf("InsertTodoItem() got error %v, want success", err)
+		}
+	}
+
+	if err := database.UpdateTodoItem(context.Background(), &models.TodoItem{TodoID: 1, Todo: "Task 1 fixed"}); err != nil {
+		t.Errorf("UpdateTodoItem() got error %v, want success", err)
+	}
+	if err := database.UpdateTodoItem(context.Background(), &models.TodoItem{TodoID: 4, Todo: "Task 4"}); err != models.ErrNotFound {
+		t.Errorf("UpdateTodoItem() got error %v, want %v", err, models.ErrNotFound)
+	}
+	if err := database.UpdateTodoItem(context.Background(), &models.TodoItem{TodoID: 3, Todo: "Task 2 fixed"}); err != nil {
+		t.Errorf("UpdateTodoItem() got error %v, want success", err)
+	}
+	if err := database.SetTodoCompleted(context.Background(), 3, true); err != nil {
+		t.Errorf("SetTodoCompleted() got error %v, want success", err)
+	}
+	if err := database.SetTodoPriority(context.Background(), 1, models.PriorityLow); err != nil {
+		t.Errorf("SetTodoPriority() got error %v, want success", err)
+	}
+	if err := database.SetTodoDueDate(context.Background(), 1, &testingDueDate); err != nil {
+		t.Errorf("SetTodoDueDate() got error %v, want success", err)
+	}
+	if err := database.SetTodoDueDate(context.Background(), 4, nil); err != models.ErrNotFound {
+		t.Errorf("SetTodoDueDate() got error %v, want %v", err, models.ErrNotFound)
+	}
+
+	got, err := database.GetUserTodosPage(context.Background(), 1, 0, 10)
+	if err != nil {
+		t.Fatalf("GetUserTodosPage() got error %v, want success", err)
+	}
+	want := []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1 fixed", DueDate: &testingDueDate, Priority: models.PriorityLow},
+		//the text update keeps the due date and priority
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2 fixed", DueDate: &testingDueDate, Priority: models.PriorityHigh, Completed: true},
+	}
+	if diff := cmp.Diff(want, got, ignoreTimestamps, cmpopts.IgnoreFields(models.TodoItem{}, "CompletedAt")); diff != "" {
+		t.Errorf("GetUserTodosPage() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+	if len(got) == 2 && (got[1].CompletedAt == nil || got[1].CreatedAt.IsZero()) {
+		t.Errorf("GetUserTodosPage() got CompletedAt %v CreatedAt %v, want both set", got[1].CompletedAt, got[1].CreatedAt)
+	}
+
+	if err := database.DeleteTodoItem(context.Background(), 2); err != nil {
+		t.Errorf("DeleteTodoItem() got error %v, want success", err)
+	}
+	if _, err := database.GetTodoItem(context.Background(), 2); err != models.ErrNotFound {
+		t.Errorf("GetTodoItem() got error %v, want %v", err, models.ErrNotFound)
+	}
+
+	if err := database.Truncate(context.Background()); err != nil {
+		t.Fatalf("Truncate() got error %v, want success", err)
+	}
+	id, err := database.InsertTodoItem(context.Background(), &models.TodoItem{UserID: 1, Todo: "Task 1"})
+	if err != nil || id != 1 {
+		t.Errorf("InsertTodoItem() after Truncate() got id %d error %v, want id 1", id, err)
+	}
+}
+
+//TestSQLiteUserTodosVersion checks the triggers change the version of the owner of the written todo only
+func TestSQLiteUserTodosVersion(t *testing.T) {
+	ctx := context.Background()
+
+	testData := []struct {
+		desc        string
+		write       func(database *SQLiteDatabase)
+		wantChanged bool
+	}{
+		{desc: "insert", write: func(database *SQLiteDatabase) {
+			database.InsertTodoItem(ctx, &models.TodoItem{UserID: 1, Todo: "Task 3"})
+		}, wantChanged: true},
+		{desc: "update", write: func(database *SQLiteDatabase) {
+			database.UpdateTodoItem(ctx, &models.TodoItem{TodoID: 1, Todo: "Task 1 fixed"})
+		}, wantChanged: true},
+		{desc: "complete", write: func(database *SQLiteDatabase) { database.SetTodoCompleted(ctx, 1, true) }, wantChanged: true},
+		{desc: "delete", write: func(database *SQLiteDatabase) { database.DeleteTodoItem(ctx, 1) }, wantChanged: true},
+		{desc: "delete user todos", write: func(database *SQLiteDatabase) { database.DeleteUserTodos(ctx, 1) }, wantChanged: true},
+		{desc: "truncate", write: func(database *SQLiteDatabase) { database.Truncate(ctx) }, wantChanged: true},
+		{desc: "direct sql", write: func(database *SQLiteDatabase) { database.db.Exec("UPDATE todos SET Todo = 'Task 0' WHERE TodoID = 1") }, wantChanged: true},
+		{desc: "todo of another user", write: func(database *SQLiteDatabase) { database.SetTodoCompleted(ctx, 2, true) }, wantChanged: false},
+		{desc: "missing todo", write: func(database *SQLiteDatabase) { database.DeleteTodoItem(ctx, 4) }, wantChanged: false},
+	}
+
+	for _, tc := range testData {
+		database := openSQLite(t)
+		for _, todo := range []*models.TodoItem{
+			&models.TodoItem{UserID: 1, Todo: "Task 1"},
+			&models.TodoItem{UserID: 2, Todo: "Task 1"},
+		} {
+			if _, err := database.InsertTodoItem(ctx, todo); err != nil {
+				t.Fatalf("InsertTodoItem() got error %v, want success", err)
+			}
+		}
+		before, _ := database.GetUserTodosVersion(ctx, 1)
+
+		tc.write(database)
+		got, err := database.GetUserTodosVersion(ctx, 1)
+
+		if err != nil {
+			t.Errorf("[%q]: GetUserTodosVersion() got error %v, want success", tc.desc, err)
+			continue
+		}
+		if changed := got != before; changed != tc.wantChanged || got == 0 {
+			t.Errorf("[%q]: GetUserTodosVersion() got %d after %d, want changed %v", tc.desc, got, before, tc.wantChanged)
+		}
+	}
+}
+
+//TestSQLiteUserTodosVersionRecreated checks versions don't repeat after the table is dropped and recreated
+func TestSQLiteUserTodosVersionRecreated(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+	if _, err := database.InsertTodoItem(ctx, &models.TodoItem{UserID: 1, Todo: "Task 1"}); err != nil {
+		t.Fatalf("InsertTodoItem() got error %v, want success", err)
+	}
+	before, _ := database.GetUserTodosVersion(ctx, 1)
+
+	//SQLite reads the clock in milliseconds
+	time.Sleep(2 * time.Millisecond)
+	if _, err := database.MigrateDown(); err != nil {
+		t.Fatalf("MigrateDown() got error %v, want success", err)
+	}
+	if _, err := database.MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp() got error %v, want success", err)
+	}
+	got, err := database.GetUserTodosVersion(ctx, 1)
+
+	if err != nil || got <= before {
+		t.Errorf("GetUserTodosVersion() after recreating the versions got %d error %v, want more than %d", got, err, before)
+	}
+}
+
+func TestSQLiteCanceledContext(t *testing.T) {
+	database := openSQLite(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := database.GetAllTodos(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAllTodos() got error %v, want %v", err, context.Canceled)
+	}
+	if _, err := database.InsertTodoItem(ctx, &models.TodoItem{UserID: 1, Todo: "Task 1"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("InsertTodoItem() got error %v, want %v", err, context.Canceled)
+	}
+}
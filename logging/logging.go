@@ -0,0 +1,112 @@
+//Package logging attaches a request scoped slog logger to every call and keeps todo text out of the logs
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+//RequestIDKey is the metadata key of the request ID, it is taken from the caller or generated
+//and sent back in the response header
+const RequestIDKey = "x-request-id"
+
+//maxRequestIDLength bounds caller supplied request IDs so they can't flood the logs
+const maxRequestIDLength = 64
+
+type contextKey struct{}
+
+//NewContext returns a context carrying the logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+//FromContext returns the logger of the request, or the default logger outside of a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+//requestID returns the request ID of the caller when it's usable, a new one otherwise
+func requestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(RequestIDKey); len(ids) > 0 && validRequestID(ids[0]) {
+		return ids[0]
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+//validRequestID accepts printable ASCII only, so IDs can't forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+//start attaches the request logger to ctx and returns the function logging the result of the call
+func start(ctx context.Context, method string) (context.Context, func(error)) {
+	id := requestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+	logger := slog.Default().With("request_id", id, "method", method)
+	begin := time.Now()
+	return NewContext(ctx, logger), func(err error) {
+		code := status.Code(err)
+		level := slog.LevelInfo
+		if err != nil {
+			level = slog.LevelWarn
+		}
+		logger.Log(ctx, level, "finished call", "code", code.String(), "duration", time.Since(begin), "error", errorMessage(err))
+	}
+}
+
+//errorMessage is the status message of err, it is empty on success
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return status.Convert(err).Message()
+}
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, done := start(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := start(stream.Context(), info.FullMethod)
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		done(err)
+		return err
+	}
+}
+
+//contextStream replaces the context of a stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (this *contextStream) Context() context.Context {
+	return this.ctx
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+//captureDefault installs a JSON default logger writing to the returned buffer until the test ends
+func captureDefault(t *testing.T, redact bool) *bytes.Buffer {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), redact)))
+	return &buf
+}
+
+func TestUnaryServerInterceptorRequestID(t *testing.T) {
+	testData := []struct {
+		desc      string
+		requestID string
+		wantID    string
+	}{
+		{desc: "from metadata", requestID: "abc-123", wantID: "abc-123"},
+		{desc: "generated", requestID: "", wantID: ""},
+		{desc: "control characters", requestID: "abc\ninjected", wantID: ""},
+		{desc: "too long", requestID: strings.Repeat("a", maxRequestIDLength+1), wantID: ""},
+	}
+
+	for _, tc := range testData {
+		buf := captureDefault(t, true)
+		ctx := context.Background()
+		if tc.requestID != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RequestIDKey, tc.requestID))
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			FromContext(ctx).Info("handling")
+			return nil, nil
+		}
+
+		UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/todo.TodoService/AddTodo"}, handler)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Errorf("[%q]: interceptor logged %d lines, want 2", tc.desc, len(lines))
+			continue
+		}
+		var ids []string
+		for _, line := range lines {
+			var entry map[string]interface{}
+			json.Unmarshal([]byte(line), &entry)
+			id, _ := entry["request_id"].(string)
+			ids = append(ids, id)
+		}
+		if ids[0] == "" || ids[0] != ids[1] {
+			t.Errorf("[%q]: interceptor logged request IDs %q, want the same ID on every line", tc.desc, ids)
+		}
+		if tc.wantID != "" && ids[0] != tc.wantID {
+			t.Errorf("[%q]: interceptor got request ID %q, want %q", tc.desc, ids[0], tc.wantID)
+		}
+		if tc.wantID == "" && ids[0] == tc.requestID {
+			t.Errorf("[%q]: interceptor kept the invalid request ID %q", tc.desc, tc.requestID)
+		}
+	}
+}
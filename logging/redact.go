@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//redacted replaces the value of sensitive fields
+const redacted = "[REDACTED]"
+
+//sensitiveFields hold user content, they are redacted unless logging todo content is enabled
+var sensitiveFields = map[protoreflect.Name]bool{
+	"todo": true,
+}
+
+//Handler renders protobuf messages logged as attribute values field by field and redacts
+//their sensitive fields, e.g. the text of todo items, when redact is set
+type Handler struct {
+	slog.Handler
+	redact bool
+}
+
+func NewHandler(handler slog.Handler, redact bool) *Handler {
+	return &Handler{Handler: handler, redact: redact}
+}
+
+func (this *Handler) Handle(ctx context.Context, record slog.Record) error {
+	rendered := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		rendered.AddAttrs(this.attr(attr))
+		return true
+	})
+	return this.Handler.Handle(ctx, rendered)
+}
+
+func (this *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	rendered := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		rendered[i] = this.attr(attr)
+	}
+	return &Handler{Handler: this.Handler.WithAttrs(rendered), redact: this.redact}
+}
+
+func (this *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: this.Handler.WithGroup(name), redact: this.redact}
+}
+
+//attr renders the protobuf messages in attr, groups are searched recursively
+func (this *Handler) attr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		rendered := make([]slog.Attr, len(group))
+		for i, a := range group {
+			rendered[i] = this.attr(a)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(rendered...)}
+	case slog.KindAny:
+		if message, ok := value.Any().(proto.Message); ok {
+			return slog.Attr{Key: attr.Key, Value: this.message(message.ProtoReflect())}
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+//message returns a group of the populated fields of message
+func (this *Handler) message(message protoreflect.Message) slog.Value {
+	if !message.IsValid() {
+		return slog.AnyValue(nil)
+	}
+	if ts, ok := message.Interface().(*timestamppb.Timestamp); ok {
+		return slog.TimeValue(ts.AsTime())
+	}
+	var attrs []slog.Attr
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		attrs = append(attrs, slog.Attr{Key: string(field.Name()), Value: this.field(field, value)})
+		return true
+	})
+	return slog.GroupValue(attrs...)
+}
+
+func (this *Handler) field(field protoreflect.FieldDescriptor, value protoreflect.Value) slog.Value {
+	switch {
+	case field.IsList():
+		list := value.List()
+		items := make([]slog.Attr, list.Len())
+		for i := range items {
+			items[i] = slog.Attr{Key: strconv.Itoa(i), Value: this.single(field, list.Get(i))}
+		}
+		return slog.GroupValue(items...)
+	case field.IsMap():
+		var entries []slog.Attr
+		value.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			entries = append(entries, slog.Attr{Key: key.String(), Value: this.single(field.MapValue(), value)})
+			return true
+		})
+		return slog.GroupValue(entries...)
+	}
+	return this.single(field, value)
+}
+
+//single renders one value of a field
+func (this *Handler) single(field protoreflect.FieldDescriptor, value protoreflect.Value) slog.Value {
+	if this.redact && sensitiveFields[field.Name()] {
+		return slog.StringValue(redacted)
+	}
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return this.message(value.Message())
+	case protoreflect.EnumKind:
+		if enum := field.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			return slog.StringValue(string(enum.Name()))
+		}
+		return slog.Int64Value(int64(value.Enum()))
+	case protoreflect.BytesKind:
+		return slog.IntValue(len(value.Bytes()))
+	}
+	return slog.AnyValue(value.Interface())
+}
@@ -0,0 +1,39 @@
+package logging_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"todo-app/logging"
+	"todo-app/todo"
+)
+
+func TestHandlerRedaction(t *testing.T) {
+	message := &todo.GetUserTodosResponse{Items: []*todo.TodoItem{{TodoID: 3, UserID: 7, Todo: "see the doctor"}}}
+
+	testData := []struct {
+		desc      string
+		redact    bool
+		want      string
+		wantNever string
+	}{
+		{desc: "redacted", redact: true, want: `"todo":"[REDACTED]"`, wantNever: "doctor"},
+		{desc: "todo content", redact: false, want: `"todo":"see the doctor"`, wantNever: "REDACTED"},
+	}
+
+	for _, tc := range testData {
+		var buf bytes.Buffer
+		logger := slog.New(logging.NewHandler(slog.NewJSONHandler(&buf, nil), tc.redact))
+
+		logger.With("request", message).Info("sending", "response", message)
+
+		got := buf.String()
+		if strings.Count(got, tc.want) != 2 || strings.Contains(got, tc.wantNever) {
+			t.Errorf("[%q]: logged %s, want %s twice and no %q", tc.desc, got, tc.want, tc.wantNever)
+		}
+		if !strings.Contains(got, `"todoID":3`) {
+			t.Errorf("[%q]: logged %s, want the todo id", tc.desc, got)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+//streamType returns the grpc_type label of a stream
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
+
+//observe counts a started RPC and returns the function recording its result
+func (this *Metrics) observe(fullMethod string, rpcType string) func(error) {
+	service, method := splitMethod(fullMethod)
+	labels := prometheus.Labels{"grpc_service": service, "grpc_method": method, "grpc_type": rpcType}
+	this.rpcStarted.With(labels).Inc()
+	start := time.Now()
+	return func(err error) {
+		this.rpcDuration.With(labels).Observe(time.Since(start).Seconds())
+		this.rpcHandled.MustCurryWith(labels).WithLabelValues(status.Code(err).String()).Inc()
+	}
+}
+
+func (this *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done := this.observe(info.FullMethod, "unary")
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+//StreamServerInterceptor records the stream like a unary call and counts its messages
+func (this *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		rpcType := streamType(info)
+		done := this.observe(info.FullMethod, rpcType)
+		service, method := splitMethod(info.FullMethod)
+		counted := &countingStream{
+			ServerStream: stream,
+			received:     this.streamReceived.WithLabelValues(service, method, rpcType),
+			sent:         this.streamSent.WithLabelValues(service, method, rpcType),
+		}
+		err := handler(srv, counted)
+		done(err)
+		return err
+	}
+}
+
+type countingStream struct {
+	grpc.ServerStream
+	received prometheus.Counter
+	sent     prometheus.Counter
+}
+
+func (this *countingStream) SendMsg(message interface{}) error {
+	err := this.ServerStream.SendMsg(message)
+	if err == nil {
+		this.sent.Inc()
+	}
+	return err
+}
+
+func (this *countingStream) RecvMsg(message interface{}) error {
+	err := this.ServerStream.RecvMsg(message)
+	if err == nil {
+		this.received.Inc()
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+//Package metrics exports Prometheus metrics of the gRPC calls, the data store, the hash workers and the transform strategies
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+//Metrics holds the collectors, it is registered on its own registry so tests can create several
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcStarted     *prometheus.CounterVec
+	rpcHandled     *prometheus.CounterVec
+	rpcDuration    *prometheus.HistogramVec
+	streamReceived *prometheus.CounterVec
+	streamSent     *prometheus.CounterVec
+	queryDuration  *prometheus.HistogramVec
+	fanOut         prometheus.Histogram
+	activeWorkers  prometheus.Gauge
+	transform      *prometheus.HistogramVec
+	transformItems *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	rpcLabels := []string{"grpc_service", "grpc_method", "grpc_type"}
+	this := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_started_total",
+			Help: "RPCs started on the server.",
+		}, rpcLabels),
+		rpcHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "RPCs completed on the server by status code.",
+		}, append(rpcLabels, "grpc_code")),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Time until the server completed RPCs.",
+			Buckets: prometheus.DefBuckets,
+		}, rpcLabels),
+		streamReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Stream messages received from clients.",
+		}, rpcLabels),
+		streamSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Stream messages sent to clients.",
+		}, rpcLabels),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "todo_datastore_query_seconds",
+			Help:    "Latency of DataStore methods by result.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "result"}),
+		fanOut: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "todo_hash_fanout_goroutines",
+			Help:    "Goroutines started by one hash request.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+		}),
+		activeWorkers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "todo_hash_workers_active",
+			Help: "Hash goroutines currently running.",
+		}),
+		transform: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "todo_transform_seconds",
+			Help:    "Latency of hash transform strategies by result.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"strategy", "result"}),
+		transformItems: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "todo_transform_items_total",
+			Help: "Todo items hashed by each transform strategy.",
+		}, []string{"strategy"}),
+	}
+	this.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		this.rpcStarted, this.rpcHandled, this.rpcDuration, this.streamReceived, this.streamSent,
+		this.queryDuration, this.fanOut, this.activeWorkers, this.transform, this.transformItems,
+	)
+	return this
+}
+
+//RegisterDB exports the connection pool statistics of the database
+func (this *Metrics) RegisterDB(db *sql.DB, name string) {
+	this.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+//Handler serves the metrics in the Prometheus exposition format
+func (this *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(this.registry, promhttp.HandlerOpts{Registry: this.registry})
+}
+
+//FanOut records the goroutines started for one hash request, it implements todo.FanOutObserver
+func (this *Metrics) FanOut(n int) func() {
+	this.fanOut.Observe(float64(n))
+	this.activeWorkers.Add(float64(n))
+	return func() {
+		this.activeWorkers.Sub(float64(n))
+	}
+}
+
+//Transform records the latency and items of one hash transform strategy, it implements todo.TransformObserver
+func (this *Metrics) Transform(strategy string, items int) func(error) {
+	start := time.Now()
+	return func(err error) {
+		this.transform.WithLabelValues(strategy, result(err)).Observe(time.Since(start).Seconds())
+		this.transformItems.WithLabelValues(strategy).Add(float64(items))
+	}
+}
+
+//splitMethod splits /package.Service/Method into its service and method
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
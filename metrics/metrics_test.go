@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/models"
+	"todo-app/store/memstore"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	testData := []struct {
+		desc     string
+		err      error
+		wantCode string
+	}{
+		{desc: "success", err: nil, wantCode: "OK"},
+		{desc: "status error", err: status.Error(codes.NotFound, "todo item not found"), wantCode: "NotFound"},
+		{desc: "plain error", err: errors.New("boom"), wantCode: "Unknown"},
+	}
+
+	for _, tc := range testData {
+		m := New()
+		info := &grpc.UnaryServerInfo{FullMethod: "/todo.TodoService/GetTodo"}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, tc.err
+		}
+
+		m.UnaryServerInterceptor()(context.Background(), nil, info, handler)
+
+		started := testutil.ToFloat64(m.rpcStarted.WithLabelValues("todo.TodoService", "GetTodo", "unary"))
+		handled := testutil.ToFloat64(m.rpcHandled.WithLabelValues("todo.TodoService", "GetTodo", "unary", tc.wantCode))
+		if started != 1 || handled != 1 {
+			t.Errorf("[%q]: interceptor got %v started and %v handled with code %s, want 1 and 1", tc.desc, started, handled, tc.wantCode)
+		}
+	}
+}
+
+//sendStream accepts every message
+type sendStream struct {
+	grpc.ServerStream
+}
+
+func (this *sendStream) SendMsg(message interface{}) error {
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	m := New()
+	info := &grpc.StreamServerInfo{FullMethod: "/todo.TodoService/GetUserTodos", IsServerStream: true}
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			stream.SendMsg(nil)
+		}
+		return nil
+	}
+
+	m.StreamServerInterceptor()(nil, &sendStream{}, info, handler)
+
+	if got := testutil.ToFloat64(m.streamSent.WithLabelValues("todo.TodoService", "GetUserTodos", "server_stream")); got != 3 {
+		t.Errorf("StreamServerInterceptor() counted %v sent messages, want 3", got)
+	}
+	if got := testutil.ToFloat64(m.rpcHandled.WithLabelValues("todo.TodoService", "GetUserTodos", "server_stream", "OK")); got != 1 {
+		t.Errorf("StreamServerInterceptor() counted %v handled streams, want 1", got)
+	}
+}
+
+//queryCount returns how many queries of the method with the result were observed
+func queryCount(t *testing.T, m *Metrics, method string, result string) uint64 {
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() got error %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "todo_datastore_query_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["method"] == method && labels["result"] == result {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestStore(t *testing.T) {
+	m := New()
+	ds := m.Store(memstore.New())
+	ctx := context.Background()
+
+	id, err := ds.InsertTodoItem(ctx, &models.TodoItem{UserID: 1, Todo: "metrics"})
+	if err != nil {
+		t.Fatalf("InsertTodoItem() got error %v", err)
+	}
+	ds.GetTodoItem(ctx, id)
+	ds.GetTodoItem(ctx, id+1)
+
+	testData := []struct {
+		method string
+		result string
+		want   uint64
+	}{
+		{method: "InsertTodoItem", result: "ok", want: 1},
+		{method: "GetTodoItem", result: "ok", want: 1},
+		{method: "GetTodoItem", result: "not_found", want: 1},
+		{method: "DeleteTodoItem", result: "ok", want: 0},
+	}
+	for _, tc := range testData {
+		if got := queryCount(t, m, tc.method, tc.result); got != tc.want {
+			t.Errorf("[%s %s]: Store() recorded %d queries, want %d", tc.method, tc.result, got, tc.want)
+		}
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	m := New()
+
+	done := m.FanOut(4)
+	if got := testutil.ToFloat64(m.activeWorkers); got != 4 {
+		t.Errorf("FanOut() got %v active workers, want 4", got)
+	}
+	done()
+	if got := testutil.ToFloat64(m.activeWorkers); got != 0 {
+		t.Errorf("FanOut() done got %v active workers, want 0", got)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	m := New()
+
+	m.Transform("indexing", 3)(nil)
+	m.Transform("indexing", 2)(context.DeadlineExceeded)
+	m.Transform("append", 1)(nil)
+
+	if got := testutil.ToFloat64(m.transformItems.WithLabelValues("indexing")); got != 5 {
+		t.Errorf("Transform() counted %v indexing items, want 5", got)
+	}
+	if got := testutil.CollectAndCount(m.transform); got != 3 {
+		t.Errorf("Transform() recorded %d strategy and result series, want 3", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/todo.TodoService/AddTodo"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	recorder := httptest.NewRecorder()
+
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := recorder.Body.String()
+	for _, want := range []string{`grpc_server_handled_total{grpc_code="OK",grpc_method="AddTodo"`, "go_goroutines", "todo_hash_workers_active"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Handler() response misses %q", want)
+		}
+	}
+}
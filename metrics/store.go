@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/models"
+	"todo-app/todo"
+)
+
+//store decorates a DataStore and records the latency of its methods
+type store struct {
+	ds      todo.DataStore
+	metrics *Metrics
+}
+
+//Store wraps the data store so the latency of every method is recorded by method and result
+func (this *Metrics) Store(ds todo.DataStore) todo.DataStore {
+	return &store{ds: ds, metrics: this}
+}
+
+//observe returns the function recording the latency of a call started now
+func (this *store) observe(method string) func(error) {
+	start := time.Now()
+	return func(err error) {
+		this.metrics.queryDuration.WithLabelValues(method, result(err)).Observe(time.Since(start).Seconds())
+	}
+}
+
+//result labels an error without the unbounded error text
+func result(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, models.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, models.ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+func (this *store) InsertTodoItem(ctx context.Context, item *models.TodoItem) (int32, error) {
+	done := this.observe("InsertTodoItem")
+	id, err := this.ds.InsertTodoItem(ctx, item)
+	done(err)
+	return id, err
+}
+
+func (this *store) GetAllTodos(ctx context.Context) ([]*models.TodoItem, error) {
+	done := this.observe("GetAllTodos")
+	items, err := this.ds.GetAllTodos(ctx)
+	done(err)
+	return items, err
+}
+
+func (this *store) GetUserTodos(ctx context.Context, userID int32) ([]*models.TodoItem, error) {
+	done := this.observe("GetUserTodos")
+	items, err := this.ds.GetUserTodos(ctx, userID)
+	done(err)
+	return items, err
+}
+
+func (this *store) GetAllTodosPage(ctx context.Context, afterID int32, limit int) ([]*models.TodoItem, error) {
+	done := this.observe("GetAllTodosPage")
+	items, err := this.ds.GetAllTodosPage(ctx, afterID, limit)
+	done(err)
+	return items, err
+}
+
+func (this *store) GetUserTodosPage(ctx context.Context, userID int32, afterID int32, limit int) ([]*models.TodoItem, error) {
+	done := this.observe("GetUserTodosPage")
+	items, err := this.ds.GetUserTodosPage(ctx, userID, afterID, limit)
+	done(err)
+	return items, err
+}
+
+func (this *store) DeleteUserTodos(ctx context.Context, userID int32) error {
+	done := this.observe("DeleteUserTodos")
+	err := this.ds.DeleteUserTodos(ctx, userID)
+	done(err)
+	return err
+}
+
+func (this *store) GetTodoItem(ctx context.Context, todoID int32) (*models.TodoItem, error) {
+	done := this.observe("GetTodoItem")
+	item, err := this.ds.GetTodoItem(ctx, todoID)
+	done(err)
+	return item, err
+}
+
+func (this *store) UpdateTodoItem(ctx context.Context, item *models.TodoItem) error {
+	done := this.observe("UpdateTodoItem")
+	err := this.ds.UpdateTodoItem(ctx, item)
+	done(err)
+	return err
+}
+
+func (this *store) SetTodoCompleted(ctx context.Context, todoID int32, completed bool) error {
+	done := this.observe("SetTodoCompleted")
+	err := this.ds.SetTodoCompleted(ctx, todoID, completed)
+	done(err)
+	return err
+}
+
+func (this *store) SetTodoDueDate(ctx context.Context, todoID int32, dueDate *time.Time) error {
+	done := this.observe("SetTodoDueDate")
+	err := this.ds.SetTodoDueDate(ctx, todoID, dueDate)
+	done(err)
+	return err
+}
+
+func (this *store) SetTodoPriority(ctx context.Context, todoID int32, priority models.Priority) error {
+	done := this.observe("SetTodoPriority")
+	err := this.ds.SetTodoPriority(ctx, todoID, priority)
+	done(err)
+	return err
+}
+
+func (this *store) DeleteTodoItem(ctx context.Context, todoID int32) error {
+	done := this.observe("DeleteTodoItem")
+	err := this.ds.DeleteTodoItem(ctx, todoID)
+	done(err)
+	return err
+}
+
+func (this *store) Truncate(ctx context.Context) error {
+	done := this.observe("Truncate")
+	err := this.ds.Truncate(ctx)
+	done(err)
+	return err
+}
+
+func (this *store) GetUserTodosVersion(ctx context.Context, userID int32) (int64, error) {
+	done := this.observe("GetUserTodosVersion")
+	version, err := this.ds.GetUserTodosVersion(ctx, userID)
+	done(err)
+	return version, err
+}
+
+func (this *store) Ping(ctx context.Context) error {
+	done := this.observe("Ping")
+	err := this.ds.Ping(ctx)
+	done(err)
+	return err
+}
+
+func (this *store) Close() error {
+	return this.ds.Close()
+}
@@ -1,7 +1,47 @@
 package models
 
+import (
+	"errors"
+	"time"
+)
+
+//ErrNotFound returned by a data store when no todo item matches the given id
+var ErrNotFound = errors.New("todo item not found")
+
+//ErrUnavailable wraps data store errors caused by a lost or refused connection, the call may be retried
+var ErrUnavailable = errors.New("data store unavailable")
+
+//FieldError returned by a data store when it rejects the value of a todo item field
+type FieldError struct {
+	//Field is the name of the TodoItem field
+	Field       string
+	Description string
+}
+
+func (this *FieldError) Error() string {
+	return "invalid " + this.Field + ": " + this.Description
+}
+
+//Priority of a todo item, values match the Priority enum in todo.proto
+type Priority int32
+
+const (
+	PriorityUnspecified Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
 type TodoItem struct {
-	TodoID int32
-	UserID int32
-	Todo   string
+	TodoID    int32
+	UserID    int32
+	Todo      string
+	Completed bool
+	//CompletedAt is nil while the item is not completed
+	CompletedAt *time.Time
+	//DueDate is nil when the item has no due date
+	DueDate   *time.Time
+	Priority  Priority
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
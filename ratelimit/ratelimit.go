@@ -0,0 +1,217 @@
+//Package ratelimit limits the calls of each caller with token buckets per method
+//and caps the number of streams a caller keeps open
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo-app/auth"
+	"todo-app/config"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+//RetryAfterKey is the trailer telling rejected callers how many seconds to wait
+const RetryAfterKey = "retry-after"
+
+//streamRetryAfter is suggested to callers at the stream cap, when a stream closes isn't known
+const streamRetryAfter = time.Second
+
+//sweepInterval is how often buckets that refilled completely are dropped
+const sweepInterval = time.Minute
+
+type bucketKey struct {
+	caller string
+	method string
+}
+
+type Limiter struct {
+	mu      sync.Mutex
+	limits  config.RateLimit
+	global  *rate.Limiter
+	buckets map[bucketKey]*rate.Limiter
+	//streams counts the open streams of each caller
+	streams   map[string]int
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func New(limits config.RateLimit) *Limiter {
+	this := &Limiter{streams: map[string]int{}, now: time.Now}
+	this.Update(limits)
+	return this
+}
+
+//Update replaces the limits, the buckets start full again while open streams stay counted
+func (this *Limiter) Update(limits config.RateLimit) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.limits = limits
+	this.global = newBucket(limits.Global)
+	this.buckets = map[bucketKey]*rate.Limiter{}
+	this.lastSweep = this.now()
+}
+
+//newBucket returns nil for unlimited budgets
+func newBucket(limit config.Limit) *rate.Limiter {
+	if limit.Unlimited() {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+}
+
+//take removes a token from the bucket, it returns how long to wait when the bucket is empty
+//the returned function puts the token back
+func take(bucket *rate.Limiter, now time.Time) (time.Duration, func()) {
+	if bucket == nil {
+		return 0, func() {}
+	}
+	reservation := bucket.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay, nil
+	}
+	return 0, func() { reservation.CancelAt(now) }
+}
+
+//allow takes a token of the caller's budget of the method and of the global budget
+//it returns how long the caller should wait when either is exhausted
+func (this *Limiter) allow(caller string, method string) time.Duration {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	now := this.now()
+	if now.Sub(this.lastSweep) > sweepInterval {
+		this.sweep(now)
+	}
+
+	key := bucketKey{caller: caller, method: method}
+	bucket, ok := this.buckets[key]
+	if !ok {
+		limit, ok := this.limits.Methods[method]
+		if !ok {
+			limit = this.limits.Default
+		}
+		bucket = newBucket(limit)
+		this.buckets[key] = bucket
+	}
+	delay, undo := take(bucket, now)
+	if delay > 0 {
+		return delay
+	}
+	//a call rejected by the global budget doesn't count against the caller
+	if delay, _ := take(this.global, now); delay > 0 {
+		undo()
+		return delay
+	}
+	return 0
+}
+
+//sweep drops the buckets that are full again, they behave like new ones
+func (this *Limiter) sweep(now time.Time) {
+	for key, bucket := range this.buckets {
+		if bucket == nil || bucket.TokensAt(now) >= float64(bucket.Burst()) {
+			delete(this.buckets, key)
+		}
+	}
+	this.lastSweep = now
+}
+
+//openStream counts a stream of the caller, it reports false when the caller is at the cap
+func (this *Limiter) openStream(caller string) bool {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.limits.MaxStreams > 0 && this.streams[caller] >= this.limits.MaxStreams {
+		return false
+	}
+	this.streams[caller]++
+	return true
+}
+
+func (this *Limiter) closeStream(caller string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.streams[caller]--
+	if this.streams[caller] <= 0 {
+		delete(this.streams, caller)
+	}
+}
+
+//caller identifies the authenticated user, or the peer address of anonymous callers
+func caller(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return "user:" + identity.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		//the port changes with every connection
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "peer:" + host
+		}
+		return "peer:" + p.Addr.String()
+	}
+	return "unknown"
+}
+
+//methodName returns Method of /package.Service/Method
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+//exempt methods are the gRPC health checks and reflection, load balancers must always reach them
+func exempt(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.")
+}
+
+//exhausted returns a ResourceExhausted error and sets the retry-after trailer in whole seconds
+func exhausted(ctx context.Context, retryAfter time.Duration, message string) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, strconv.Itoa(seconds)))
+	st, err := status.New(codes.ResourceExhausted, message).WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+	return st.Err()
+}
+
+//UnaryServerInterceptor must run after authentication so calls are limited per user
+func (this *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if exempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if delay := this.allow(caller(ctx), methodName(info.FullMethod)); delay > 0 {
+			return nil, exhausted(ctx, delay, "rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+//StreamServerInterceptor limits opening streams like unary calls and caps the open streams of each caller
+func (this *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if exempt(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		ctx := stream.Context()
+		who := caller(ctx)
+		if delay := this.allow(who, methodName(info.FullMethod)); delay > 0 {
+			return exhausted(ctx, delay, "rate limit exceeded")
+		}
+		if !this.openStream(who) {
+			return exhausted(ctx, streamRetryAfter, "too many open streams")
+		}
+		defer this.closeStream(who)
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,214 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+	"todo-app/auth"
+	"todo-app/config"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+//clock is advanced by the tests
+type clock struct {
+	now time.Time
+}
+
+func (this *clock) Now() time.Time {
+	return this.now
+}
+
+func newLimiter(limits config.RateLimit) (*Limiter, *clock) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	limiter := New(limits)
+	limiter.now = c.Now
+	//restarts the sweep interval on the test clock
+	limiter.Update(limits)
+	return limiter, c
+}
+
+func userContext(subject string) context.Context {
+	return auth.NewContext(context.Background(), &auth.Identity{Subject: subject})
+}
+
+func peerContext(address string) context.Context {
+	addr, _ := net.ResolveTCPAddr("tcp", address)
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	limits := config.RateLimit{
+		Global:  config.Limit{Rate: 100, Burst: 4},
+		Default: config.Limit{Rate: 1, Burst: 2},
+		Methods: map[string]config.Limit{"AddTodo": {Rate: 1, Burst: 1}},
+	}
+
+	testData := []struct {
+		desc      string
+		calls     []context.Context
+		method    string
+		wantCodes []codes.Code
+	}{
+		{
+			desc:      "default budget",
+			calls:     []context.Context{userContext("alice"), userContext("alice"), userContext("alice")},
+			method:    "/todo.TodoService/GetTodo",
+			wantCodes: []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+		{
+			desc:      "method budget",
+			calls:     []context.Context{userContext("alice"), userContext("alice")},
+			method:    "/todo.TodoService/AddTodo",
+			wantCodes: []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+		{
+			desc:      "budget per user",
+			calls:     []context.Context{userContext("alice"), userContext("bob")},
+			method:    "/todo.TodoService/AddTodo",
+			wantCodes: []codes.Code{codes.OK, codes.OK},
+		},
+		{
+			desc:      "budget per peer host",
+			calls:     []context.Context{peerContext("10.0.0.1:4000"), peerContext("10.0.0.1:4001"), peerContext("10.0.0.2:4000")},
+			method:    "/todo.TodoService/AddTodo",
+			wantCodes: []codes.Code{codes.OK, codes.ResourceExhausted, codes.OK},
+		},
+		{
+			desc:      "global budget",
+			calls:     []context.Context{userContext("a"), userContext("b"), userContext("c"), userContext("d"), userContext("e")},
+			method:    "/todo.TodoService/GetTodo",
+			wantCodes: []codes.Code{codes.OK, codes.OK, codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+		{
+			desc:      "health checks are exempt",
+			calls:     []context.Context{userContext("alice"), userContext("alice"), userContext("alice")},
+			method:    "/grpc.health.v1.Health/Check",
+			wantCodes: []codes.Code{codes.OK, codes.OK, codes.OK},
+		},
+	}
+
+	for _, tc := range testData {
+		limiter, _ := newLimiter(limits)
+		interceptor := limiter.UnaryServerInterceptor()
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		}
+
+		for i, ctx := range tc.calls {
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+
+			if code := status.Code(err); code != tc.wantCodes[i] {
+				t.Errorf("[%q]: call %d got code %v, want %v", tc.desc, i, code, tc.wantCodes[i])
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	limiter, clock := newLimiter(config.RateLimit{Default: config.Limit{Rate: 0.5, Burst: 1}})
+	interceptor := limiter.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/todo.TodoService/AddTodo"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	ctx := userContext("alice")
+
+	interceptor(ctx, nil, info, handler)
+	_, err := interceptor(ctx, nil, info, handler)
+
+	var retryInfo *errdetails.RetryInfo
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retryInfo = info
+		}
+	}
+	if retryInfo == nil || retryInfo.RetryDelay.AsDuration() != 2*time.Second {
+		t.Errorf("rejected call got details %v, want a retry delay of 2s", status.Convert(err).Details())
+	}
+
+	//the bucket refills after the delay
+	clock.now = clock.now.Add(2 * time.Second)
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Errorf("call after the retry delay got error %v, want success", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	limiter, _ := newLimiter(config.RateLimit{Default: config.Limit{Rate: 1, Burst: 1}})
+
+	if limiter.allow("user:alice", "AddTodo") != 0 || limiter.allow("user:alice", "AddTodo") == 0 {
+		t.Fatalf("allow() didn't exhaust a budget of one call")
+	}
+	limiter.Update(config.RateLimit{})
+	for i := 0; i < 10; i++ {
+		if delay := limiter.allow("user:alice", "AddTodo"); delay != 0 {
+			t.Errorf("allow() after removing the limits got delay %v, want 0", delay)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	limiter, clock := newLimiter(config.RateLimit{Default: config.Limit{Rate: 1, Burst: 1}})
+	limiter.allow("user:alice", "AddTodo")
+	limiter.allow("user:bob", "AddTodo")
+
+	clock.now = clock.now.Add(sweepInterval + time.Second)
+	limiter.allow("user:carol", "AddTodo")
+
+	if got := len(limiter.buckets); got != 1 {
+		t.Errorf("sweep kept %d buckets, want only the one of carol", got)
+	}
+}
+
+//contextStream is a stream of the caller in ctx
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (this *contextStream) Context() context.Context {
+	return this.ctx
+}
+
+func TestStreamServerInterceptorMaxStreams(t *testing.T) {
+	limiter, _ := newLimiter(config.RateLimit{MaxStreams: 2})
+	interceptor := limiter.StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/todo.TodoService/GetUserTodos", IsClientStream: true, IsServerStream: true}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			results <- interceptor(nil, &contextStream{ctx: userContext("alice")}, info, handler)
+		}()
+		<-started
+	}
+
+	err := interceptor(nil, &contextStream{ctx: userContext("alice")}, info, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("third stream got error %v, want ResourceExhausted", err)
+	}
+	if err := interceptor(nil, &contextStream{ctx: userContext("bob")}, info, func(interface{}, grpc.ServerStream) error { return nil }); err != nil {
+		t.Errorf("stream of another user got error %v, want success", err)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		<-results
+	}
+	if len(limiter.streams) != 0 {
+		t.Errorf("closed streams are still counted: %v", limiter.streams)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo-app/todo"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+//watchHealth pings the data store every interval and sets the serving status
+//of the server and of TodoService until ctx is done
+func watchHealth(ctx context.Context, ds todo.DataStore, healthServer *health.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	serving := true
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		//a ping hanging longer than the interval counts as unhealthy
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := ds.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if serving != (err == nil) {
+			serving = err == nil
+			log.Printf("Data store health changed to %v : %v", status, err)
+		}
+		healthServer.SetServingStatus("", status)
+		healthServer.SetServingStatus(todo.TodoService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"todo-app/todo"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+//pingStore is a data store whose Ping result can be changed by the test
+type pingStore struct {
+	todo.DataStore
+	mu  sync.Mutex
+	err error
+}
+
+func (this *pingStore) Ping(ctx context.Context) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.err
+}
+
+func (this *pingStore) setErr(err error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.err = err
+}
+
+//waitForStatus polls the health server until the service reports want or the timeout expires
+func waitForStatus(t *testing.T, healthServer *health.Server, service string, want healthpb.HealthCheckResponse_ServingStatus) {
+	deadline := time.Now().Add(time.Second)
+	var got healthpb.HealthCheckResponse_ServingStatus
+	for time.Now().Before(deadline) {
+		response, err := healthServer.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err == nil {
+			got = response.Status
+			if got == want {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("health of %q got %v, want %v", service, got, want)
+}
+
+func TestWatchHealth(t *testing.T) {
+	store := &pingStore{}
+	healthServer := health.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go watchHealth(ctx, store, healthServer, time.Millisecond)
+
+	service := todo.TodoService_ServiceDesc.ServiceName
+	waitForStatus(t, healthServer, service, healthpb.HealthCheckResponse_SERVING)
+
+	store.setErr(errors.New("connection refused"))
+	waitForStatus(t, healthServer, service, healthpb.HealthCheckResponse_NOT_SERVING)
+	waitForStatus(t, healthServer, "", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	store.setErr(nil)
+	waitForStatus(t, healthServer, service, healthpb.HealthCheckResponse_SERVING)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"todo-app/config"
+	"todo-app/logging"
+)
+
+//setupLogging installs the default slog logger, the standard log package writes through it at info level
+//todo text in logged messages is redacted unless the config enables it
+func setupLogging(cfg config.Log) {
+	var level slog.Level
+	//config.Validate only accepts levels known to slog
+	level.UnmarshalText([]byte(cfg.Level))
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(logging.NewHandler(handler, !cfg.TodoContent)))
+}
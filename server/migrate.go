@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"todo-app/db/migrate"
+	"todo-app/todo"
+)
+
+//migrator is implemented by data stores with a versioned schema
+type migrator interface {
+	MigrateUp() (int, error)
+	MigrateDown() (*migrate.Migration, error)
+	MigrationStatus() ([]migrate.State, error)
+}
+
+//runMigrate runs the migrate up, down or status command against the data store
+func runMigrate(ds todo.DataStore, command string) error {
+	m, ok := ds.(migrator)
+	if !ok {
+		return errors.New("data store has no schema migrations")
+	}
+	switch command {
+	case "up":
+		applied, err := m.MigrateUp()
+		log.Printf("Applied %d migrations", applied)
+		return err
+	case "down":
+		migration, err := m.MigrateDown()
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			log.Printf("No migration to roll back")
+			return nil
+		}
+		log.Printf("Rolled back migration %04d_%s", migration.Version, migration.Name)
+		return nil
+	case "status":
+		states, err := m.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			applied := "pending"
+			if state.Applied {
+				applied = "applied"
+			}
+			fmt.Printf("%04d_%s\t%s\n", state.Version, state.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, must be up, down or status", command)
+	}
+}
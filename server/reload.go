@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"todo-app/config"
+	"todo-app/ratelimit"
+)
+
+//reloadOnHangup reloads the configuration on SIGHUP and applies its rate limits until ctx is done
+//the other settings need a restart
+func reloadOnHangup(ctx context.Context, limiter *ratelimit.Limiter) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			cfg, _, err := config.Load(fs, os.Args[1:])
+			if err != nil {
+				log.Printf("Error when reloading config, keeping the rate limits : %v", err)
+				continue
+			}
+			limiter.Update(cfg.RateLimit)
+			log.Printf("Reloaded rate limits")
+		}
+	}
+}
@@ -1,33 +1,225 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	"log"
 	"net"
-	"time"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"todo-app/auth"
+	"todo-app/config"
 	"todo-app/db"
+	"todo-app/logging"
+	"todo-app/metrics"
+	"todo-app/ratelimit"
+	"todo-app/store/memstore"
 	"todo-app/todo"
+	"todo-app/tracing"
+	"todo-app/validate"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  %[1]s [flags]\n  %[1]s [flags] migrate up|down|status\n\nFlags:\n", "server")
+	flag.PrintDefaults()
+}
+
+//openStore returns the data store backend selected by the config
+func openStore(cfg *config.Config) (todo.DataStore, error) {
+	switch cfg.Store {
+	case "mysql":
+		return db.GetDB(cfg.MySQLDSN)
+	case "sqlite":
+		return db.GetSQLiteDB(cfg.SQLitePath)
+	case "memory":
+		return memstore.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q, must be mysql, sqlite or memory", cfg.Store)
+	}
+}
+
+//requireAuth reports whether callers must be identified by a bearer token or a client certificate
+func requireAuth(cfg *config.Config) bool {
+	return cfg.Auth.Enabled() || cfg.TLS.Mutual()
+}
+
+//dbStore is implemented by data stores backed by a database/sql connection pool
+type dbStore interface {
+	DB() *sql.DB
+}
+
+func serverOptions(cfg *config.Config, m *metrics.Metrics, limiter *ratelimit.Limiter) ([]grpc.ServerOption, error) {
+	//the stats handler starts a span for every RPC, continuing the trace of the caller
+	opts := []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+	//metrics come first so rejected calls are counted too
+	if m != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(m.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(m.StreamServerInterceptor()))
+	}
+	//the request logger is attached before authentication so rejected calls are logged with their request ID
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor()))
+	if cfg.TLS.Enabled() {
+		tlsCfg, err := tlsConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	if requireAuth(cfg) {
+		var verifier *auth.Verifier
+		if cfg.Auth.Enabled() {
+			var err error
+			verifier, err = auth.LoadVerifier(cfg.Auth.HMACKeyFile, cfg.Auth.RSAPublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(verifier)),
+			grpc.ChainStreamInterceptor(auth.StreamServerInterceptor(verifier)))
+	}
+	//callers are limited after authentication so each user has its own budget
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(limiter.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(limiter.StreamServerInterceptor()))
+	//validation runs after authentication so unauthenticated callers learn nothing about the rules
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(validate.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(validate.StreamServerInterceptor()))
+	return opts, nil
+}
+
 func main() {
-	lis, err := net.Listen("tcp", ":9000")
+	flag.Usage = usage
+	cfg, args, err := config.Load(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		log.Printf("Failed to listen to port 9009 : %v", err)
+		log.Printf("Error when loading config : %v", err)
+		return
+	}
+	setupLogging(cfg.Log)
+	//the algorithms and transforms are registered in the todo package, config can't check them
+	if !slices.Contains(todo.HashAlgorithms(), cfg.HashAlgorithm) {
+		log.Printf("Unknown hash algorithm %q, must be one of %v", cfg.HashAlgorithm, todo.HashAlgorithms())
+		return
+	}
+	if !slices.Contains(todo.Transformers(), cfg.HashTransform) {
+		log.Printf("Unknown hash transform %q, must be one of %v", cfg.HashTransform, todo.Transformers())
 		return
 	}
 
-	database, err := db.GetDB("testdb")
+	ds, err := openStore(cfg)
 	if err != nil {
-		log.Printf("Error when connecting to database : %v", err)
+		log.Printf("Error when opening data store : %v", err)
 		return
 	}
-	s := todo.Server{DS: database, WaitingTime: time.Second}
+	//deferred first so it runs last, after the server stopped using the store
+	defer func() {
+		if err := ds.Close(); err != nil {
+			log.Printf("Error when closing data store : %v", err)
+		}
+	}()
 
-	grpcServer := grpc.NewServer()
+	if len(args) > 0 {
+		if args[0] != "migrate" || len(args) != 2 {
+			usage()
+			return
+		}
+		if err := runMigrate(ds, args[1]); err != nil {
+			log.Printf("Migrate %s failed : %v", args[1], err)
+		}
+		return
+	}
+
+	lis, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		log.Printf("Failed to listen on %s : %v", cfg.ListenAddress, err)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "todo-server", cfg.Tracing.Exporter, cfg.Tracing.Endpoint)
+	if err != nil {
+		log.Printf("Error when setting up tracing : %v", err)
+		return
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Printf("Error when flushing traces : %v", err)
+		}
+	}()
+
+	s := todo.Server{
+		DS:            tracing.Store(ds, cfg.Store),
+		HashAlgorithm: cfg.HashAlgorithm,
+		Transform:     cfg.HashTransform,
+		WaitingTime:   cfg.WaitingTime,
+		RequireAuth:   requireAuth(cfg),
+		MaxWorkers:    cfg.HashWorkers,
+		Pool:          todo.NewPool(cfg.HashWorkersTotal),
+	}
+
+	var m *metrics.Metrics
+	if cfg.MetricsAddress != "" {
+		m = metrics.New()
+		if pool, ok := ds.(dbStore); ok {
+			m.RegisterDB(pool.DB(), cfg.Store)
+		}
+		s.DS = m.Store(s.DS)
+		s.FanOut = m
+		s.Transforms = m
+		if err := serveAdmin(ctx, cfg.MetricsAddress, m); err != nil {
+			log.Printf("Failed to listen on %s : %v", cfg.MetricsAddress, err)
+			return
+		}
+	}
+
+	limiter := ratelimit.New(cfg.RateLimit)
+	go reloadOnHangup(ctx, limiter)
+
+	opts, err := serverOptions(cfg, m, limiter)
+	if err != nil {
+		log.Printf("Error when loading server credentials : %v", err)
+		return
+	}
+	grpcServer := grpc.NewServer(opts...)
 	todo.RegisterTodoServiceServer(grpcServer, &s)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+
+	go watchHealth(ctx, s.DS, healthServer, cfg.HealthInterval)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		//report NOT_SERVING so load balancers stop sending requests while streams drain
+		healthServer.Shutdown()
+		s.StopWatching()
+		log.Printf("Shutting down, waiting up to %v for open streams", cfg.ShutdownTimeout)
+		if !gracefulStop(grpcServer, cfg.ShutdownTimeout) {
+			log.Printf("Shutdown timeout expired, stopped open streams")
+		}
+	}()
 
 	if err := grpcServer.Serve(lis); err != nil {
-		log.Printf("Failed to serve gRPC server over port 9000 : %v", err)
+		log.Printf("Failed to serve gRPC server over %s : %v", cfg.ListenAddress, err)
+		stop()
 	}
+	<-stopped
 }
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+//gracefulStop stops accepting new RPCs and waits up to timeout for open RPCs and streams to finish
+//then stops the server forcefully, it reports whether all RPCs finished in time
+func gracefulStop(server *grpc.Server, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		//cancels the contexts of open streams, GracefulStop returns after that
+		server.Stop()
+		<-done
+		return false
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+	"todo-app/models"
+	"todo-app/store/memstore"
+	"todo-app/todo"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+//startStreaming serves a store with three todos and opens a GetAllTodosStreaming stream
+func startStreaming(t *testing.T, waitingTime time.Duration) (*grpc.Server, todo.TodoService_GetAllTodosStreamingClient) {
+	store := memstore.New()
+	for i := 0; i < 3; i++ {
+		store.InsertTodoItem(context.Background(), &models.TodoItem{UserID: 1, Todo: "Task"})
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	todo.RegisterTodoServiceServer(server, &todo.Server{DS: store, WaitingTime: waitingTime})
+	go server.Serve(listener)
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	stream, err := todo.NewTodoServiceClient(conn).GetAllTodosStreaming(context.Background(), &todo.NoParams{})
+	if err != nil {
+		t.Fatalf("GetAllTodosStreaming() got error %v, want success", err)
+	}
+	//wait for the first item so the stream is open before stopping
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv() got error %v, want success", err)
+	}
+	return server, stream
+}
+
+//receiveAll returns the number of items received before the stream ended and the final error
+func receiveAll(stream todo.TodoService_GetAllTodosStreamingClient) (int, error) {
+	count := 0
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+func TestGracefulStopDrainsStreams(t *testing.T) {
+	server, stream := startStreaming(t, 20*time.Millisecond)
+
+	result := make(chan error, 1)
+	go func() {
+		count, err := receiveAll(stream)
+		if err == nil && count != 2 {
+			t.Errorf("received %d more items, want 2", count)
+		}
+		result <- err
+	}()
+
+	if !gracefulStop(server, time.Second) {
+		t.Errorf("gracefulStop() got false, want open stream to finish in time")
+	}
+	if err := <-result; err != nil {
+		t.Errorf("stream got error %v, want success", err)
+	}
+}
+
+func TestGracefulStopForcesSlowStreams(t *testing.T) {
+	server, stream := startStreaming(t, 200*time.Millisecond)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := receiveAll(stream)
+		result <- err
+	}()
+
+	start := time.Now()
+	if gracefulStop(server, 10*time.Millisecond) {
+		t.Errorf("gracefulStop() got true, want slow stream to be stopped")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gracefulStop() took %v, want about the timeout", elapsed)
+	}
+	if err := <-result; err == nil {
+		t.Errorf("stream got success, want an error")
+	}
+}
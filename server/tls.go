@@ -0,0 +1,100 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+	"todo-app/config"
+)
+
+//certReloader serves the certificate from disk and reloads it on the next handshake after the files change
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertReloader(certFile string, keyFile string) (*certReloader, error) {
+	reloader := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+//lastModified returns the latest modification time of the certificate and key files
+func (this *certReloader) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, file := range []string{this.certFile, this.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last, nil
+}
+
+//reload loads the certificate when the files changed since the last load
+func (this *certReloader) reload() error {
+	modTime, err := this.lastModified()
+	if err != nil {
+		return err
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.cert != nil && modTime.Equal(this.modTime) {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(this.certFile, this.keyFile)
+	if err != nil {
+		return err
+	}
+	this.cert = &cert
+	this.modTime = modTime
+	return nil
+}
+
+//GetCertificate keeps serving the previous certificate when reloading fails, e.g. while the files are rewritten
+func (this *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if err := this.reload(); err != nil {
+		log.Printf("Error when reloading TLS certificate %s : %v", this.certFile, err)
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.cert, nil
+}
+
+//tlsConfig returns the server TLS config, client certificates are required when a client CA is set
+func tlsConfig(cfg config.TLS) (*tls.Config, error) {
+	reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if cfg.Mutual() {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in client ca file %s", cfg.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsCfg, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-app/config"
+	"todo-app/ratelimit"
+	"todo-app/store/memstore"
+	"todo-app/todo"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+//testCA issues certificates for the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate key %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("cannot create ca %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, ca.path("ca.pem"), "CERTIFICATE", der)
+	return ca
+}
+
+func (this *testCA) path(name string) string {
+	return filepath.Join(this.dir, name)
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("cannot write %s %v", path, err)
+	}
+}
+
+//issue writes a certificate and key signed by the CA to name.pem and name.key
+func (this *testCA) issue(t *testing.T, name string, serial int64, subject pkix.Name, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate key %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, this.cert, &key.PublicKey, this.key)
+	if err != nil {
+		t.Fatalf("cannot create certificate %v", err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("cannot marshal key %v", err)
+	}
+	certFile, keyFile := this.path(name+".pem"), this.path(name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "PRIVATE KEY", keyDer)
+	return certFile, keyFile
+}
+
+func serialOf(t *testing.T, reloader *certReloader) int64 {
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate() got error %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("cannot parse certificate %v", err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, "server", 10, pkix.Name{CommonName: "localhost"}, x509.ExtKeyUsageServerAuth)
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("newCertReloader() got error %v", err)
+	}
+	if serial := serialOf(t, reloader); serial != 10 {
+		t.Errorf("GetCertificate() got serial %d, want 10", serial)
+	}
+
+	//renewed certificate is served once the files change
+	ca.issue(t, "server", 11, pkix.Name{CommonName: "localhost"}, x509.ExtKeyUsageServerAuth)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	if serial := serialOf(t, reloader); serial != 11 {
+		t.Errorf("GetCertificate() after renewal got serial %d, want 11", serial)
+	}
+
+	//a broken certificate keeps the previous one
+	os.WriteFile(certFile, []byte("broken"), 0600)
+	later = later.Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	if serial := serialOf(t, reloader); serial != 11 {
+		t.Errorf("GetCertificate() after broken renewal got serial %d, want 11", serial)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "server", 2, pkix.Name{CommonName: "localhost"}, x509.ExtKeyUsageServerAuth)
+	userCert, userKey := ca.issue(t, "user", 3, pkix.Name{CommonName: "1"}, x509.ExtKeyUsageClientAuth)
+	adminCert, adminKey := ca.issue(t, "admin", 4, pkix.Name{CommonName: "ops", OrganizationalUnit: []string{"admin"}}, x509.ExtKeyUsageClientAuth)
+
+	cfg := config.Default()
+	cfg.TLS = config.TLS{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: ca.path("ca.pem")}
+	opts, err := serverOptions(&cfg, nil, ratelimit.New(cfg.RateLimit))
+	if err != nil {
+		t.Fatalf("serverOptions() got error %v", err)
+	}
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(opts...)
+	todo.RegisterTodoServiceServer(server, &todo.Server{DS: memstore.New(), RequireAuth: requireAuth(&cfg)})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	testData := []struct {
+		desc     string
+		certFile string
+		keyFile  string
+		userID   int32
+		wantCode codes.Code
+	}{
+		{desc: "own todos", certFile: userCert, keyFile: userKey, userID: 1, wantCode: codes.OK},
+		{desc: "other user todos", certFile: userCert, keyFile: userKey, userID: 2, wantCode: codes.PermissionDenied},
+		{desc: "admin", certFile: adminCert, keyFile: adminKey, userID: 2, wantCode: codes.OK},
+		{desc: "no client certificate", certFile: "", keyFile: "", userID: 1, wantCode: codes.Unavailable},
+	}
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	for _, tc := range testData {
+		tlsCfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if tc.certFile != "" {
+			cert, err := tls.LoadX509KeyPair(tc.certFile, tc.keyFile)
+			if err != nil {
+				t.Fatalf("cannot load client certificate %v", err)
+			}
+			tlsCfg.Certificates = []tls.Certificate{cert}
+		}
+		conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+		if err != nil {
+			t.Fatalf("Failed to dial bufnet: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err = todo.NewTodoServiceClient(conn).AddTodo(ctx, &todo.AddTodoRequest{Item: &todo.TodoItem{UserID: tc.userID, Todo: "Task"}})
+		cancel()
+		conn.Close()
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: AddTodo() got code %v, want %v (%v)", tc.desc, code, tc.wantCode, err)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+//Package memstore implements an in-memory todo.DataStore, for local runs and tests without a database
+package memstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"todo-app/models"
+)
+
+//Store keeps todos in memory ordered by todo id, it is safe for concurrent use
+//its operations never block so the contexts of the DataStore methods are ignored
+type Store struct {
+	mu     sync.RWMutex
+	lastID int32
+	todos  []*models.TodoItem
+	//versions changes with the writes to the todos of each user
+	versions map[int32]int64
+}
+
+//New returns an empty store, the first inserted todo gets id 1
+func New() *Store {
+	return &Store{versions: make(map[int32]int64)}
+}
+
+//copyTodo returns a copy so callers can't modify stored items
+func copyTodo(item *models.TodoItem) *models.TodoItem {
+	res := *item
+	res.CompletedAt = copyTime(item.CompletedAt)
+	res.DueDate = copyTime(item.DueDate)
+	return &res
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	res := *t
+	return &res
+}
+
+//find returns the index of the todo with the given id, or -1
+func (this *Store) find(todoID int32) int {
+	idx := sort.Search(len(this.todos), func(i int) bool {
+		return this.todos[i].TodoID >= todoID
+	})
+	if idx < len(this.todos) && this.todos[idx].TodoID == todoID {
+		return idx
+	}
+	return -1
+}
+
+//touch changes the version of the todos of the user, the caller holds the write lock
+//versions are the time in microseconds, or one more than the previous version, so a store
+//of a previous process doesn't repeat them
+func (this *Store) touch(userID int32) {
+	version := time.Now().UnixMicro()
+	if previous := this.versions[userID]; version <= previous {
+		version = previous + 1
+	}
+	this.versions[userID] = version
+}
+
+//InsertTodoItem stores a copy of the item with the next id and sets its CreatedAt and UpdatedAt
+func (this *Store) InsertTodoItem(ctx context.Context, item *models.TodoItem) (int32, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	now := time.Now().UTC()
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	this.lastID++
+	stored := copyTodo(item)
+	stored.TodoID = this.lastID
+	this.todos = append(this.todos, stored)
+	this.touch(stored.UserID)
+	return stored.TodoID, nil
+}
+
+//filter returns copies of up to limit todos after afterID accepted by keep, a negative limit means no limit
+func (this *Store) filter(afterID int32, limit int, keep func(*models.TodoItem) bool) []*models.TodoItem {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	todos := make([]*models.TodoItem, 0)
+	start := sort.Search(len(this.todos), func(i int) bool {
+		return this.todos[i].TodoID > afterID
+	})
+	for _, todo := range this.todos[start:] {
+		if limit >= 0 && len(todos) == limit {
+			break
+		}
+		if keep(todo) {
+			todos = append(todos, copyTodo(todo))
+		}
+	}
+	return todos
+}
+
+func all(*models.TodoItem) bool {
+	return true
+}
+
+func ofUser(userID int32) func(*models.TodoItem) bool {
+	return func(todo *models.TodoItem) bool {
+		return todo.UserID == userID
+	}
+}
+
+func (this *Store) GetAllTodos(ctx context.Context) ([]*models.TodoItem, error) {
+	return this.filter(0, -1, all), nil
+}
+
+func (this *Store) GetUserTodos(ctx context.Context, userID int32) ([]*models.TodoItem, error) {
+	return this.filter(0, -1, ofUser(userID)), nil
+}
+
+func (this *Store) GetAllTodosPage(ctx context.Context, afterID int32, limit int) ([]*models.TodoItem, error) {
+	return this.filter(afterID, limit, all), nil
+}
+
+func (this *Store) GetUserTodosPage(ctx context.Context, userID int32, afterID int32, limit int) ([]*models.TodoItem, error) {
+	return this.filter(afterID, limit, ofUser(userID)), nil
+}
+
+func (this *Store) GetTodoItem(ctx context.Context, todoID int32) (*models.TodoItem, error) {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	idx := this.find(todoID)
+	if idx == -1 {
+		return nil, models.ErrNotFound
+	}
+	return copyTodo(this.todos[idx]), nil
+}
+
+func (this *Store) DeleteUserTodos(ctx context.Context, userID int32) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	kept := this.todos[:0]
+	for _, todo := range this.todos {
+		if todo.UserID != userID {
+			kept = append(kept, todo)
+		}
+	}
+	if len(kept) < len(this.todos) {
+		this.touch(userID)
+	}
+	for i := len(kept); i < len(this.todos); i++ {
+		this.todos[i] = nil
+	}
+	this.todos = kept
+	return nil
+}
+
+//UpdateTodoItem updates the text of the todo item with the same id
+func (this *Store) UpdateTodoItem(ctx context.Context, item *models.TodoItem) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	idx := this.find(item.TodoID)
+	if idx == -1 {
+		return models.ErrNotFound
+	}
+	todo := this.todos[idx]
+	todo.Todo = item.Todo
+	todo.UpdatedAt = time.Now().UTC()
+	this.touch(todo.UserID)
+	return nil
+}
+
+//SetTodoCompleted marks the todo item as completed now, or clears its completion
+func (this *Store) SetTodoCompleted(ctx context.Context, todoID int32, completed bool) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	idx := this.find(todoID)
+	if idx == -1 {
+		return models.ErrNotFound
+	}
+	now := time.Now().UTC()
+	todo := this.todos[idx]
+	todo.Completed = completed
+	todo.CompletedAt = nil
+	if completed {
+		todo.CompletedAt = &now
+	}
+	todo.UpdatedAt = now
+	this.touch(todo.UserID)
+	return nil
+}
+
+//SetTodoDueDate sets the due date of the todo item, nil clears it
+func (this *Store) SetTodoDueDate(ctx context.Context, todoID int32, dueDate *time.Time) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	idx := this.find(todoID)
+	if idx == -1 {
+		return models.ErrNotFound
+	}
+	todo := this.todos[idx]
+	todo.DueDate = copyTime(dueDate)
+	todo.UpdatedAt = time.Now().UTC()
+	this.touch(todo.UserID)
+	return nil
+}
+
+//SetTodoPriority sets the priority of the todo item
+func (this *Store) SetTodoPriority(ctx context.Context, todoID int32, priority models.Priority) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	idx := this.find(todoID)
+	if idx == -1 {
+		return models.ErrNotFound
+	}
+	todo := this.todos[idx]
+	todo.Priority = priority
+	todo.UpdatedAt = time.Now().UTC()
+	this.touch(todo.UserID)
+	return nil
+}
+
+func (this *Store) DeleteTodoItem(ctx context.Context, todoID int32) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	idx := this.find(todoID)
+	if idx == -1 {
+		return models.ErrNotFound
+	}
+	this.touch(this.todos[idx].UserID)
+	copy(this.todos[idx:], this.todos[idx+1:])
+	this.todos[len(this.todos)-1] = nil
+	this.todos = this.todos[:len(this.todos)-1]
+	return nil
+}
+
+//GetUserTodosVersion returns the version of the todos of the user, it changes with every write to them
+func (this *Store) GetUserTodosVersion(ctx context.Context, userID int32) (int64, error) {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	return this.versions[userID], nil
+}
+
+//Ping always succeeds
+func (this *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+//Close does nothing, the store keeps its todos until garbage collected
+func (this *Store) Close() error {
+	return nil
+}
+
+//Truncate removes all todos and restarts ids from 1, the versions keep counting
+func (this *Store) Truncate(ctx context.Context) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	for userID := range this.versions {
+		this.touch(userID)
+	}
+	this.todos = nil
+	this.lastID = 0
+	return nil
+}
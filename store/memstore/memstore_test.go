@@ -0,0 +1,285 @@
+package memstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"todo-app/models"
+	"todo-app/todo"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var _ todo.DataStore = (*Store)(nil)
+
+//ignoreTimestamps ignores the timestamps set by the store on insert and update
+var ignoreTimestamps = cmpopts.IgnoreFields(models.TodoItem{}, "CreatedAt", "UpdatedAt")
+
+func setup(t *testing.T, initialTodos []*models.TodoItem) *Store {
+	store := New()
+	for _, todo := range initialTodos {
+		_, err := store.InsertTodoItem(context.Background(), todo)
+		if err != nil {
+			t.Errorf("Error in setup store %v", err)
+		}
+	}
+	return store
+}
+
+//TestInsertTodoItem checks generated ids are sequential
+func TestInsertTodoItem(t *testing.T) {
+	store := setup(t, []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+	})
+
+	id, err := store.InsertTodoItem(context.Background(), &models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2"})
+
+	if err != nil {
+		t.Fatalf("InsertTodoItem() got error %v, want success", err)
+	}
+	if id != 3 {
+		t.Errorf("InsertTodoItem() got id %d, want 3", id)
+	}
+
+	store.Truncate(context.Background())
+	id, _ = store.InsertTodoItem(context.Background(), &models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"})
+	if id != 1 {
+		t.Errorf("InsertTodoItem() after Truncate() got id %d, want 1", id)
+	}
+}
+
+//TestInsertTodoItemConcurrent checks concurrent inserts get unique ids
+func TestInsertTodoItemConcurrent(t *testing.T) {
+	const n = 100
+	store := New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.InsertTodoItem(context.Background(), &models.TodoItem{UserID: 1, Todo: "Task"})
+		}()
+	}
+	wg.Wait()
+
+	todos, _ := store.GetAllTodos(context.Background())
+	if len(todos) != n {
+		t.Fatalf("GetAllTodos() got %d todos, want %d", len(todos), n)
+	}
+	for i, todo := range todos {
+		if todo.TodoID != int32(i+1) {
+			t.Errorf("GetAllTodos() got id %d at index %d, want %d", todo.TodoID, i, i+1)
+		}
+	}
+}
+
+//TestGetUserTodos checks user todos are filtered and paged by id
+func TestGetUserTodos(t *testing.T) {
+	env := []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2"},
+		&models.TodoItem{TodoID: -1, UserID: 3, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 3"},
+	}
+
+	testData := []struct {
+		desc    string
+		userID  int32
+		afterID int32
+		limit   int
+		wantRes []*models.TodoItem
+	}{
+		{
+			desc:    "No user todos",
+			userID:  4,
+			afterID: 0,
+			limit:   -1,
+			wantRes: []*models.TodoItem{},
+		},
+		{
+			desc:    "all user todos",
+			userID:  1,
+			afterID: 0,
+			limit:   -1,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 5, UserID: 1, Todo: "Task 3"},
+			},
+		},
+		{
+			desc:    "first page",
+			userID:  1,
+			afterID: 0,
+			limit:   2,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+			},
+		},
+		{
+			desc:    "last page",
+			userID:  1,
+			afterID: 3,
+			limit:   2,
+			wantRes: []*models.TodoItem{
+				&models.TodoItem{TodoID: 5, UserID: 1, Todo: "Task 3"},
+			},
+		},
+	}
+
+	store := setup(t, env)
+
+	for _, tc := range testData {
+
+		var got []*models.TodoItem
+		if tc.limit < 0 {
+			got, _ = store.GetUserTodos(context.Background(), tc.userID)
+		} else {
+			got, _ = store.GetUserTodosPage(context.Background(), tc.userID, tc.afterID, tc.limit)
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, ignoreTimestamps); diff != "" {
+			t.Errorf("[%q]: GetUserTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+//TestGetAllTodosPage checks paging through all todos by id
+func TestGetAllTodosPage(t *testing.T) {
+	store := setup(t, []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2"},
+	})
+
+	got, _ := store.GetAllTodosPage(context.Background(), 1, 5)
+	want := []*models.TodoItem{
+		&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+	}
+
+	if diff := cmp.Diff(want, got, ignoreTimestamps); diff != "" {
+		t.Errorf("GetAllTodosPage() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+//TestUpdateAndDelete checks single item updates, completion and deletes
+func TestUpdateAndDelete(t *testing.T) {
+	dueDate := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := setup(t, []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 2", DueDate: &dueDate, Priority: models.PriorityHigh},
+		&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+	})
+
+	//the update sets the text only, the due date and priority of the request are ignored
+	if err := store.UpdateTodoItem(context.Background(), &models.TodoItem{TodoID: 2, Todo: "Task 2 fixed"}); err != nil {
+		t.Errorf("UpdateTodoItem() got error %v, want success", err)
+	}
+	if err := store.SetTodoPriority(context.Background(), 1, models.PriorityLow); err != nil {
+		t.Errorf("SetTodoPriority() got error %v, want success", err)
+	}
+	if err := store.SetTodoDueDate(context.Background(), 1, &dueDate); err != nil {
+		t.Errorf("SetTodoDueDate() got error %v, want success", err)
+	}
+	if err := store.SetTodoPriority(context.Background(), 4, models.PriorityLow); err != models.ErrNotFound {
+		t.Errorf("SetTodoPriority() got error %v, want %v", err, models.ErrNotFound)
+	}
+	if err := store.UpdateTodoItem(context.Background(), &models.TodoItem{TodoID: 4}); err != models.ErrNotFound {
+		t.Errorf("UpdateTodoItem() got error %v, want %v", err, models.ErrNotFound)
+	}
+	if err := store.SetTodoCompleted(context.Background(), 1, true); err != nil {
+		t.Errorf("SetTodoCompleted() got error %v, want success", err)
+	}
+	if err := store.DeleteTodoItem(context.Background(), 3); err != nil {
+		t.Errorf("DeleteTodoItem() got error %v, want success", err)
+	}
+	if err := store.DeleteTodoItem(context.Background(), 3); err != models.ErrNotFound {
+		t.Errorf("DeleteTodoItem() got error %v, want %v", err, models.ErrNotFound)
+	}
+
+	got, _ := store.GetAllTodos(context.Background())
+	want := []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Completed: true, DueDate: &dueDate, Priority: models.PriorityLow},
+		&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2 fixed", DueDate: &dueDate, Priority: models.PriorityHigh},
+	}
+
+	if diff := cmp.Diff(want, got, ignoreTimestamps, cmpopts.IgnoreFields(models.TodoItem{}, "CompletedAt")); diff != "" {
+		t.Errorf("GetAllTodos() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+	if got[0].CompletedAt == nil {
+		t.Errorf("SetTodoCompleted() did not set CompletedAt")
+	}
+
+	store.DeleteUserTodos(context.Background(), 1)
+	if got, _ := store.GetAllTodos(context.Background()); len(got) != 0 {
+		t.Errorf("DeleteUserTodos() left %d todos, want 0", len(got))
+	}
+}
+
+//TestGetTodoItemCopy checks returned items don't alias stored items
+func TestGetTodoItemCopy(t *testing.T) {
+	store := setup(t, []*models.TodoItem{
+		&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+	})
+
+	got, err := store.GetTodoItem(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetTodoItem() got error %v, want success", err)
+	}
+	got.Todo = "changed"
+
+	got, _ = store.GetTodoItem(context.Background(), 1)
+	if got.Todo != "Task 1" {
+		t.Errorf("GetTodoItem() got todo %q, want %q", got.Todo, "Task 1")
+	}
+
+	if _, err := store.GetTodoItem(context.Background(), 2); err != models.ErrNotFound {
+		t.Errorf("GetTodoItem() got error %v, want %v", err, models.ErrNotFound)
+	}
+}
+
+//TestGetUserTodosVersion checks every write changes the version of the owner of the todo only
+func TestGetUserTodosVersion(t *testing.T) {
+	ctx := context.Background()
+
+	testData := []struct {
+		desc        string
+		write       func(store *Store)
+		wantChanged bool
+	}{
+		{desc: "insert", write: func(store *Store) { store.InsertTodoItem(ctx, &models.TodoItem{UserID: 1, Todo: "Task 3"}) }, wantChanged: true},
+		{desc: "update", write: func(store *Store) { store.UpdateTodoItem(ctx, &models.TodoItem{TodoID: 1, Todo: "Task 1 fixed"}) }, wantChanged: true},
+		{desc: "complete", write: func(store *Store) { store.SetTodoCompleted(ctx, 1, true) }, wantChanged: true},
+		{desc: "delete", write: func(store *Store) { store.DeleteTodoItem(ctx, 1) }, wantChanged: true},
+		{desc: "delete user todos", write: func(store *Store) { store.DeleteUserTodos(ctx, 1) }, wantChanged: true},
+		{desc: "truncate", write: func(store *Store) { store.Truncate(ctx) }, wantChanged: true},
+		{desc: "todo of another user", write: func(store *Store) { store.SetTodoCompleted(ctx, 2, true) }, wantChanged: false},
+		{desc: "missing todo", write: func(store *Store) { store.DeleteTodoItem(ctx, 4) }, wantChanged: false},
+	}
+
+	for _, tc := range testData {
+		store := setup(t, []*models.TodoItem{
+			&models.TodoItem{TodoID: -1, UserID: 1, Todo: "Task 1"},
+			&models.TodoItem{TodoID: -1, UserID: 2, Todo: "Task 1"},
+		})
+		before, _ := store.GetUserTodosVersion(ctx, 1)
+
+		tc.write(store)
+		got, err := store.GetUserTodosVersion(ctx, 1)
+
+		if err != nil {
+			t.Errorf("[%q]: GetUserTodosVersion() got error %v, want success", tc.desc, err)
+			continue
+		}
+		if changed := got != before; changed != tc.wantChanged {
+			t.Errorf("[%q]: GetUserTodosVersion() got %d after %d, want changed %v", tc.desc, got, before, tc.wantChanged)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"todo-app/auth"
+	"todo-app/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//authorizeUser allows admins and the user owning the todos
+//calls without identity are allowed unless the server requires authentication
+func (s *Server) authorizeUser(ctx context.Context, userID int32) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return s.unauthenticated()
+	}
+	if identity.IsAdmin() || (identity.UserID != 0 && identity.UserID == userID) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%s can't access todos of user %d", identity.Subject, userID)
+}
+
+//authorizeAdmin allows admins only
+func (s *Server) authorizeAdmin(ctx context.Context) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return s.unauthenticated()
+	}
+	if identity.IsAdmin() {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is not an admin", identity.Subject)
+}
+
+//authorizeTodo allows admins and the user owning the todo item
+//items of other users are reported as not found so callers can't probe for ids
+func (s *Server) authorizeTodo(ctx context.Context, todoID int32) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return s.unauthenticated()
+	}
+	if identity.IsAdmin() {
+		return nil
+	}
+	item, err := s.DS.GetTodoItem(ctx, todoID)
+	if errors.Is(err, models.ErrNotFound) || (err == nil && (identity.UserID == 0 || item.UserID != identity.UserID)) {
+		return notFound(todoID)
+	}
+	return toStatusError(ctx, err)
+}
+
+func (s *Server) unauthenticated() error {
+	if s.RequireAuth {
+		return status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	return nil
+}
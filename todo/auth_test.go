@@ -0,0 +1,126 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"todo-app/auth"
+	"todo-app/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorization(t *testing.T) {
+	user := &auth.Identity{Subject: "1", UserID: 1}
+	admin := &auth.Identity{Subject: "ops", Roles: []string{auth.AdminRole}}
+
+	call := map[string]func(*Server, context.Context) error{
+		"AddTodo": func(s *Server, ctx context.Context) error {
+			_, err := s.AddTodo(ctx, &AddTodoRequest{Item: &TodoItem{UserID: 2, Todo: "Task 1"}})
+			return err
+		},
+		"GetAllTodos": func(s *Server, ctx context.Context) error {
+			_, err := s.GetAllTodos(ctx, &GetAllTodosRequest{})
+			return err
+		},
+		"DeleteUserTodos": func(s *Server, ctx context.Context) error {
+			_, err := s.DeleteUserTodos(ctx, &DeleteUserTodosRequest{UserID: 2})
+			return err
+		},
+		"GetUserTodos": func(s *Server, ctx context.Context) error {
+			return s.GetUserTodos(&testing_TodoService_GetUserTodosServer{ctx: ctx, inputs: []*GetUserTodosRequest{{UserID: 2}}})
+		},
+		"DeleteTodo": func(s *Server, ctx context.Context) error {
+			_, err := s.DeleteTodo(ctx, &DeleteTodoRequest{TodoID: 2})
+			return err
+		},
+		"CompleteTodo": func(s *Server, ctx context.Context) error {
+			_, err := s.CompleteTodo(ctx, &CompleteTodoRequest{TodoID: 2})
+			return err
+		},
+	}
+
+	testData := []struct {
+		desc        string
+		method      string
+		identity    *auth.Identity
+		requireAuth bool
+		wantCode    codes.Code
+	}{
+		{desc: "add for other user", method: "AddTodo", identity: user, wantCode: codes.PermissionDenied},
+		{desc: "add as admin", method: "AddTodo", identity: admin, wantCode: codes.OK},
+		{desc: "add without identity", method: "AddTodo", identity: nil, requireAuth: true, wantCode: codes.Unauthenticated},
+		{desc: "add with auth disabled", method: "AddTodo", identity: nil, wantCode: codes.OK},
+		{desc: "get all as user", method: "GetAllTodos", identity: user, wantCode: codes.PermissionDenied},
+		{desc: "get all as admin", method: "GetAllTodos", identity: admin, wantCode: codes.OK},
+		{desc: "delete other user todos", method: "DeleteUserTodos", identity: user, wantCode: codes.PermissionDenied},
+		{desc: "delete own todos", method: "DeleteUserTodos", identity: &auth.Identity{Subject: "2", UserID: 2}, wantCode: codes.OK},
+		{desc: "stream other user todos", method: "GetUserTodos", identity: user, wantCode: codes.PermissionDenied},
+		{desc: "stream as admin", method: "GetUserTodos", identity: admin, wantCode: codes.OK},
+		{desc: "delete other user item", method: "DeleteTodo", identity: user, wantCode: codes.NotFound},
+		{desc: "delete own item", method: "DeleteTodo", identity: &auth.Identity{Subject: "2", UserID: 2}, wantCode: codes.OK},
+		{desc: "complete other user item", method: "CompleteTodo", identity: user, wantCode: codes.NotFound},
+		{desc: "complete as admin", method: "CompleteTodo", identity: admin, wantCode: codes.OK},
+	}
+
+	for _, tc := range testData {
+
+		fakeDS := testingDB{}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, RequireAuth: tc.requireAuth}
+
+		fakeDS.data = []*models.TodoItem{
+			&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+		}
+
+		ctx := context.Background()
+		if tc.identity != nil {
+			ctx = auth.NewContext(ctx, tc.identity)
+		}
+
+		err := call[tc.method](&server, ctx)
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: %s() got code %v, want %v (%v)", tc.desc, tc.method, code, tc.wantCode, err)
+		}
+	}
+}
+
+//TestAuthorizeTodoStoreError checks a failing owner lookup is reported like the other data store errors
+func TestAuthorizeTodoStoreError(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "1", UserID: 1})
+	fakeDS := testingDB{err: fmt.Errorf("%w: dial tcp 10.0.0.5:3306: connect: connection refused", models.ErrUnavailable)}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+	testData := []struct {
+		desc string
+		call func() error
+	}{
+		{desc: "GetTodo", call: func() error {
+			_, err := server.GetTodo(ctx, &GetTodoRequest{TodoID: 1})
+			return err
+		}},
+		{desc: "UpdateTodo", call: func() error {
+			_, err := server.UpdateTodo(ctx, &UpdateTodoRequest{Item: &TodoItem{TodoID: 1, Todo: "Task 1"}})
+			return err
+		}},
+		{desc: "DeleteTodo", call: func() error {
+			_, err := server.DeleteTodo(ctx, &DeleteTodoRequest{TodoID: 1})
+			return err
+		}},
+		{desc: "CompleteTodo", call: func() error {
+			_, err := server.CompleteTodo(ctx, &CompleteTodoRequest{TodoID: 1})
+			return err
+		}},
+	}
+
+	for _, tc := range testData {
+		got := status.Convert(tc.call())
+
+		if got.Code() != codes.Unavailable || strings.Contains(got.Message(), "dial tcp") {
+			t.Errorf("[%q]: got %v %q, want Unavailable without the driver error", tc.desc, got.Code(), got.Message())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"todo-app/logging"
+	"todo-app/models"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//toStatusError maps data store and context errors to gRPC status errors
+//status errors pass unchanged, unknown errors are logged and reported as Internal so SQL text doesn't reach callers
+func toStatusError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var fieldErr *models.FieldError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "todo item not found")
+	case errors.Is(err, models.ErrUnavailable):
+		logging.FromContext(ctx).Warn("data store unavailable", "error", err)
+		return status.Error(codes.Unavailable, "data store unavailable, try again later")
+	case errors.As(err, &fieldErr):
+		return invalidArgument(fieldErr.Field, "invalid value")
+	}
+	logging.FromContext(ctx).Error("internal error", "error", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+//invalidArgument returns an InvalidArgument error with a BadRequest field violation
+func invalidArgument(field string, format string, args ...interface{}) error {
+	return badRequest([]*errdetails.BadRequest_FieldViolation{{Field: field, Description: fmt.Sprintf(format, args...)}})
+}
+
+//badRequest returns an InvalidArgument error listing the field violations in its message and BadRequest details
+func badRequest(violations []*errdetails.BadRequest_FieldViolation) error {
+	fields := make([]string, len(violations))
+	for i, violation := range violations {
+		fields[i] = violation.Field + ": " + violation.Description
+	}
+	st := status.New(codes.InvalidArgument, strings.Join(fields, "; "))
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+//notFound returns a NotFound error with the ResourceInfo of the todo item
+func notFound(todoID int32) error {
+	st := status.Newf(codes.NotFound, "todo item %d not found", todoID)
+	detailed, err := st.WithDetails(&errdetails.ResourceInfo{
+		ResourceType: "todo item",
+		ResourceName: strconv.FormatInt(int64(todoID), 10),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,102 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"todo-app/models"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestToStatusError(t *testing.T) {
+	testData := []struct {
+		desc        string
+		input       error
+		wantCode    codes.Code
+		wantMessage string
+		wantDetails []interface{}
+	}{
+		{
+			desc:     "nil",
+			input:    nil,
+			wantCode: codes.OK,
+		},
+		{
+			desc:        "status",
+			input:       status.Error(codes.PermissionDenied, "not an admin"),
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "not an admin",
+		},
+		{
+			desc:        "deadline",
+			input:       fmt.Errorf("query: %w", context.DeadlineExceeded),
+			wantCode:    codes.DeadlineExceeded,
+			wantMessage: "deadline exceeded",
+		},
+		{
+			desc:        "canceled",
+			input:       context.Canceled,
+			wantCode:    codes.Canceled,
+			wantMessage: "request canceled",
+		},
+		{
+			desc:        "not found",
+			input:       models.ErrNotFound,
+			wantCode:    codes.NotFound,
+			wantMessage: "todo item not found",
+		},
+		{
+			desc:        "unavailable",
+			input:       fmt.Errorf("%w: dial tcp 127.0.0.1:3306: connection refused", models.ErrUnavailable),
+			wantCode:    codes.Unavailable,
+			wantMessage: "data store unavailable, try again later",
+		},
+		{
+			desc:        "field error",
+			input:       &models.FieldError{Field: "Todo", Description: "Data too long for column 'Todo' at row 1"},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "Todo: invalid value",
+			wantDetails: []interface{}{&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "Todo", Description: "invalid value"}},
+			}},
+		},
+		{
+			desc:        "sql error",
+			input:       errors.New("Error 1146: Table 'testdb.todos' doesn't exist"),
+			wantCode:    codes.Internal,
+			wantMessage: "internal error",
+		},
+	}
+
+	for _, tc := range testData {
+		got := status.Convert(toStatusError(context.Background(), tc.input))
+
+		if got.Code() != tc.wantCode || got.Message() != tc.wantMessage {
+			t.Errorf("[%q]: toStatusError() got %v %q, want %v %q", tc.desc, got.Code(), got.Message(), tc.wantCode, tc.wantMessage)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantDetails, got.Details(), protocmp.Transform(), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("[%q]: toStatusError() returned unexpected details diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	got := status.Convert(notFound(7))
+
+	if got.Code() != codes.NotFound || got.Message() != "todo item 7 not found" {
+		t.Errorf("notFound() got %v %q, want NotFound", got.Code(), got.Message())
+	}
+	want := []interface{}{&errdetails.ResourceInfo{ResourceType: "todo item", ResourceName: "7"}}
+	if diff := cmp.Diff(want, got.Details(), protocmp.Transform()); diff != "" {
+		t.Errorf("notFound() returned unexpected details diff (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,24 @@
+package todo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+//itemTag returns the etag of a todo item, the hash GetUserTodoItemsWithHash returns for it with DefaultHashAlgorithm
+func itemTag(item *TodoItem) string {
+	fn, _ := lookupHash(DefaultHashAlgorithm)
+	return fmt.Sprintf("%016x", fn(canonicalTodo(item)))
+}
+
+//pageTag returns the etag of a page of the todos of a user from the version of the todos in the data store
+//pages of the same version have different tags
+func pageTag(version int64, p page) string {
+	h := fnv.New64a()
+	buf := binary.BigEndian.AppendUint64(make([]byte, 0, 16), uint64(version))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(p.afterID))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(p.size))
+	h.Write(buf)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
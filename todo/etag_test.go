@@ -0,0 +1,153 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"todo-app/models"
+	"todo-app/store/memstore"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestGetTodo(t *testing.T) {
+	item := &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}
+	etag := itemTag(item)
+
+	testData := []struct {
+		desc     string
+		input    *GetTodoRequest
+		wantRes  *GetTodoResponse
+		wantCode codes.Code
+	}{
+		{
+			desc:     "without etag",
+			input:    &GetTodoRequest{TodoID: 1},
+			wantRes:  &GetTodoResponse{Item: item, Etag: etag},
+			wantCode: codes.OK,
+		},
+		{
+			desc:     "matching etag",
+			input:    &GetTodoRequest{TodoID: 1, IfNoneMatch: etag},
+			wantRes:  &GetTodoResponse{Etag: etag, NotModified: true},
+			wantCode: codes.OK,
+		},
+		{
+			desc:     "stale etag",
+			input:    &GetTodoRequest{TodoID: 1, IfNoneMatch: itemTag(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 0"})},
+			wantRes:  &GetTodoResponse{Item: item, Etag: etag},
+			wantCode: codes.OK,
+		},
+		{
+			desc:     "not found",
+			input:    &GetTodoRequest{TodoID: 2},
+			wantRes:  nil,
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tc := range testData {
+		fakeDS := testingDB{data: []*models.TodoItem{&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}}}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+		got, err := server.GetTodo(context.Background(), tc.input)
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: GetTodo() got code %v, want %v", tc.desc, code, tc.wantCode)
+			continue
+		}
+		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: GetTodo() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+//getUserTodosPage sends one request on a GetUserTodos stream and returns the response
+func getUserTodosPage(t *testing.T, server *Server, request *GetUserTodosRequest) *GetUserTodosResponse {
+	stream := &testing_TodoService_GetUserTodosServer{inputs: []*GetUserTodosRequest{request}}
+	if err := server.GetUserTodos(stream); err != nil || len(stream.Results) != 1 {
+		t.Fatalf("GetUserTodos() got error %v and %d responses, want one response", err, len(stream.Results))
+	}
+	return stream.Results[0]
+}
+
+func TestGetUserTodosNotModified(t *testing.T) {
+	ctx := context.Background()
+
+	testData := []struct {
+		desc         string
+		write        func(server *Server)
+		wantModified bool
+	}{
+		{desc: "no write", write: func(server *Server) {}, wantModified: false},
+		{desc: "todo of another user", write: func(server *Server) { server.CompleteTodo(ctx, &CompleteTodoRequest{TodoID: 2}) }, wantModified: false},
+		{desc: "update", write: func(server *Server) {
+			server.UpdateTodo(ctx, &UpdateTodoRequest{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 4"}})
+		}, wantModified: true},
+		{desc: "complete", write: func(server *Server) { server.CompleteTodo(ctx, &CompleteTodoRequest{TodoID: 1}) }, wantModified: true},
+		{desc: "delete", write: func(server *Server) { server.DeleteTodo(ctx, &DeleteTodoRequest{TodoID: 3}) }, wantModified: true},
+		{desc: "delete user todos", write: func(server *Server) { server.DeleteUserTodos(ctx, &DeleteUserTodosRequest{UserID: 1}) }, wantModified: true},
+	}
+
+	for _, tc := range testData {
+		fakeDS := testingDB{data: []*models.TodoItem{
+			&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+			&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+		}}
+		server := &Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+		first := getUserTodosPage(t, server, &GetUserTodosRequest{UserID: 1})
+
+		tc.write(server)
+		got := getUserTodosPage(t, server, &GetUserTodosRequest{UserID: 1, IfNoneMatch: first.Etag})
+
+		if modified := !got.NotModified; modified != tc.wantModified {
+			t.Errorf("[%q]: GetUserTodos() got modified %v with %d items, want %v", tc.desc, modified, len(got.Items), tc.wantModified)
+		}
+		if got.NotModified && (len(got.Items) != 0 || got.Etag != first.Etag) {
+			t.Errorf("[%q]: GetUserTodos() not modified got %d items and etag %q, want none and %q", tc.desc, len(got.Items), got.Etag, first.Etag)
+		}
+		if !got.NotModified && got.Etag == first.Etag {
+			t.Errorf("[%q]: GetUserTodos() modified kept etag %q", tc.desc, got.Etag)
+		}
+	}
+}
+
+func TestGetUserTodosPageTags(t *testing.T) {
+	fakeDS := testingDB{data: []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+	}}
+	server := &Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	first := getUserTodosPage(t, server, &GetUserTodosRequest{UserID: 1, PageSize: 1})
+
+	//the client has the first page only, the etag must not match the second
+	got := getUserTodosPage(t, server, &GetUserTodosRequest{UserID: 1, PageSize: 1, PageToken: first.NextPageToken, IfNoneMatch: first.Etag})
+
+	if got.NotModified || len(got.Items) != 1 {
+		t.Errorf("GetUserTodos() of the second page with the first page's etag got not modified %v and %d items, want the item", got.NotModified, len(got.Items))
+	}
+}
+
+//TestGetUserTodosSharedStore checks a server sees the writes made through another server sharing its data store
+func TestGetUserTodosSharedStore(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	first := &Server{DS: store, WaitingTime: testingWaitingTime}
+	second := &Server{DS: store, WaitingTime: testingWaitingTime}
+	if _, err := first.AddTodo(ctx, &AddTodoRequest{Item: &TodoItem{UserID: 1, Todo: "Task 1"}}); err != nil {
+		t.Fatalf("AddTodo() got error %v", err)
+	}
+	page := getUserTodosPage(t, first, &GetUserTodosRequest{UserID: 1})
+
+	if _, err := second.CompleteTodo(ctx, &CompleteTodoRequest{TodoID: 1}); err != nil {
+		t.Fatalf("CompleteTodo() got error %v", err)
+	}
+	got := getUserTodosPage(t, first, &GetUserTodosRequest{UserID: 1, IfNoneMatch: page.Etag})
+
+	if got.NotModified || len(got.Items) != 1 || !got.Items[0].Completed {
+		t.Errorf("GetUserTodos() after a write through another server got not modified %v and items %v, want the completed item", got.NotModified, got.Items)
+	}
+}
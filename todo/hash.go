@@ -0,0 +1,137 @@
+package todo
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//DefaultHashAlgorithm is used when neither the request nor the server choose one
+const DefaultHashAlgorithm = "fnv1a"
+
+//HashFunc hashes the canonical encoding of a todo item
+type HashFunc func(canonical []byte) uint64
+
+var (
+	hashMu    sync.RWMutex
+	hashFuncs = map[string]HashFunc{
+		"fnv1a": func(canonical []byte) uint64 {
+			h := fnv.New64a()
+			h.Write(canonical)
+			return h.Sum64()
+		},
+		"xxhash": xxhash.Sum64,
+		//the first 8 bytes of the digest
+		"sha256": func(canonical []byte) uint64 {
+			sum := sha256.Sum256(canonical)
+			return binary.BigEndian.Uint64(sum[:8])
+		},
+		"crc32": func(canonical []byte) uint64 {
+			return uint64(crc32.ChecksumIEEE(canonical))
+		},
+	}
+)
+
+//RegisterHash adds an algorithm requests can choose by name, it panics when the name is taken
+func RegisterHash(name string, fn HashFunc) {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	if _, ok := hashFuncs[name]; ok {
+		panic(fmt.Sprintf("hash algorithm %q registered twice", name))
+	}
+	hashFuncs[name] = fn
+}
+
+//HashAlgorithms returns the names of the registered algorithms in order
+func HashAlgorithms() []string {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	names := make([]string, 0, len(hashFuncs))
+	for name := range hashFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupHash(name string) (HashFunc, bool) {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	fn, ok := hashFuncs[name]
+	return fn, ok
+}
+
+//hashAlgorithm validates the algorithm field of a request, empty selects the server default
+func (this *violations) hashAlgorithm(field string, name string) {
+	if _, ok := lookupHash(name); name != "" && !ok {
+		this.add(field, "unknown algorithm %q, must be one of %s", name, strings.Join(HashAlgorithms(), ", "))
+	}
+}
+
+//itemHasher is the algorithm chosen for one request
+type itemHasher struct {
+	name string
+	sum  HashFunc
+}
+
+//hasher returns the algorithm of the request, or the server default when it's empty
+func (s *Server) hasher(name string) (itemHasher, error) {
+	if name == "" {
+		name = s.HashAlgorithm
+	}
+	if name == "" {
+		name = DefaultHashAlgorithm
+	}
+	fn, ok := lookupHash(name)
+	if !ok {
+		return itemHasher{}, invalidArgument("algorithm", "unknown algorithm %q", name)
+	}
+	return itemHasher{name: name, sum: fn}, nil
+}
+
+//withAlgorithm records the algorithm in the hashed items
+func (this itemHasher) withAlgorithm(items []*TodoItemWithHash) []*TodoItemWithHash {
+	for _, item := range items {
+		item.Algorithm = this.name
+	}
+	return items
+}
+
+//canonicalTodo encodes every field of the item in field number order, integers are big endian,
+//the text is length prefixed and timestamps are a presence byte followed by seconds and nanos
+//the encoding must not change, stored hashes would no longer match
+func canonicalTodo(item *TodoItem) []byte {
+	buf := make([]byte, 0, 64+len(item.GetTodo()))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(item.GetTodoID()))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(item.GetUserID()))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(item.GetTodo())))
+	buf = append(buf, item.GetTodo()...)
+	if item.GetCompleted() {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = appendTimestamp(buf, item.GetCompletedAt())
+	buf = appendTimestamp(buf, item.GetDueDate())
+	buf = binary.BigEndian.AppendUint32(buf, uint32(item.GetPriority()))
+	buf = appendTimestamp(buf, item.GetCreatedAt())
+	buf = appendTimestamp(buf, item.GetUpdatedAt())
+	return buf
+}
+
+func appendTimestamp(buf []byte, ts *timestamppb.Timestamp) []byte {
+	if ts == nil {
+		return append(buf, 0)
+	}
+	buf = append(buf, 1)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(ts.GetSeconds()))
+	return binary.BigEndian.AppendUint32(buf, uint32(ts.GetNanos()))
+}
@@ -0,0 +1,95 @@
+package todo
+
+import (
+	"context"
+	"todo-app/logging"
+)
+
+//hashResult is a hashed item and its index in the user's todos
+type hashResult struct {
+	idx  int
+	item *TodoItemWithHash
+}
+
+//orderedSender holds back results until all earlier items were sent
+type orderedSender struct {
+	send    func(*TodoItemWithHash) error
+	next    int
+	pending map[int]*TodoItemWithHash
+}
+
+func (this *orderedSender) add(result hashResult) error {
+	this.pending[result.idx] = result.item
+	for {
+		item, ok := this.pending[this.next]
+		if !ok {
+			return nil
+		}
+		delete(this.pending, this.next)
+		this.next++
+		if err := this.send(item); err != nil {
+			return err
+		}
+	}
+}
+
+//StreamUserTodoItemsWithHash sends each hashed todo item of the user as soon as its hash is ready,
+//or in the order of GetUserTodoItemsWithHash when ordered is set. Items finished before an error,
+//e.g. the deadline, are sent before it. In ordered mode only the items before the first unfinished one are sent
+func (s *Server) StreamUserTodoItemsWithHash(message *StreamUserTodoItemsWithHashRequest, stream TodoService_StreamUserTodoItemsWithHashServer) error {
+	ctx := stream.Context()
+	logging.FromContext(ctx).Debug("received stream user todo items with hash request", "request", message)
+
+	userID := message.UserID
+	if err := s.authorizeUser(ctx, userID); err != nil {
+		return err
+	}
+	h, err := s.hasher(message.GetAlgorithm())
+	if err != nil {
+		return err
+	}
+	todos, err := s.DS.GetUserTodos(ctx, userID)
+	if err != nil {
+		return toStatusError(ctx, err)
+	}
+
+	//buffered for every item so workers never wait for a slow client
+	results := make(chan hashResult, len(todos))
+	var list []func(context.Context) error
+	for idx, todo := range todos {
+		idx := idx
+		item := toProtoTodoItem(todo)
+		list = append(list, func(ctx context.Context) error {
+			hash, err := s.computeTodoHash(ctx, h, item)
+			if err != nil {
+				return err
+			}
+			results <- hashResult{idx: idx, item: &TodoItemWithHash{Item: item, Hash: hash, Algorithm: h.name}}
+			return nil
+		})
+	}
+	hashErr := make(chan error, 1)
+	go func() {
+		hashErr <- s.parallel(ctx, message.GetMaxWorkers(), list)
+		close(results)
+	}()
+
+	add := func(result hashResult) error {
+		return stream.Send(result.item)
+	}
+	if message.Ordered {
+		ordered := &orderedSender{send: stream.Send, pending: map[int]*TodoItemWithHash{}}
+		add = ordered.add
+	}
+	var sendErr error
+	for result := range results {
+		//after a failed send the results are drained so the workers can finish
+		if sendErr == nil {
+			sendErr = add(result)
+		}
+	}
+	if err := <-hashErr; err != nil {
+		return toStatusError(ctx, err)
+	}
+	return toStatusError(ctx, sendErr)
+}
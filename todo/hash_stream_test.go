@@ -0,0 +1,128 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-app/models"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type testing_TodoService_StreamUserTodoItemsWithHashServer struct {
+	grpc.ServerStream
+	ctx     context.Context
+	Results []*TodoItemWithHash
+}
+
+func (this *testing_TodoService_StreamUserTodoItemsWithHashServer) Send(item *TodoItemWithHash) error {
+	this.Results = append(this.Results, item)
+	return nil
+}
+
+func (this *testing_TodoService_StreamUserTodoItemsWithHashServer) Context() context.Context {
+	return this.ctx
+}
+
+func TestStreamUserTodoItemsWithHash(t *testing.T) {
+	dsData := []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+		&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+	}
+	userItems := []*TodoItemWithHash{
+		hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+		hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+		hashed(&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}),
+	}
+	sortTodos := func(x, y *TodoItemWithHash) bool {
+		return x.Item.TodoID < y.Item.TodoID
+	}
+
+	testData := []struct {
+		desc     string
+		input    *StreamUserTodoItemsWithHashRequest
+		dsErr    error
+		wantRes  []*TodoItemWithHash
+		wantCode codes.Code
+		cmpOpts  []cmp.Option
+	}{
+		{
+			desc:     "completion order",
+			input:    &StreamUserTodoItemsWithHashRequest{UserID: 1},
+			wantRes:  userItems,
+			wantCode: codes.OK,
+			cmpOpts:  []cmp.Option{cmpopts.SortSlices(sortTodos)},
+		},
+		{
+			desc:     "ordered",
+			input:    &StreamUserTodoItemsWithHashRequest{UserID: 1, Ordered: true},
+			wantRes:  userItems,
+			wantCode: codes.OK,
+		},
+		{
+			desc:     "no todos",
+			input:    &StreamUserTodoItemsWithHashRequest{UserID: 4},
+			wantRes:  nil,
+			wantCode: codes.OK,
+		},
+		{
+			desc:     "data store error",
+			input:    &StreamUserTodoItemsWithHashRequest{UserID: 1},
+			dsErr:    errors.New("db failed"),
+			wantRes:  nil,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testData {
+		fakeDS := testingDB{data: dsData, err: tc.dsErr}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+		stream := &testing_TodoService_StreamUserTodoItemsWithHashServer{ctx: context.Background()}
+
+		err := server.StreamUserTodoItemsWithHash(tc.input, stream)
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: StreamUserTodoItemsWithHash() got code %v, want %v", tc.desc, code, tc.wantCode)
+			continue
+		}
+		opts := append([]cmp.Option{protocmp.Transform()}, tc.cmpOpts...)
+		if diff := cmp.Diff(tc.wantRes, stream.Results, opts...); diff != "" {
+			t.Errorf("[%q]: StreamUserTodoItemsWithHash() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+func TestStreamUserTodoItemsWithHashPartialResults(t *testing.T) {
+	fakeDS := testingDB{data: []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 3"},
+	}}
+	//one worker hashes an item every 20ms, the deadline passes while the third one is hashed
+	server := Server{DS: &fakeDS, WaitingTime: 40 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stream := &testing_TodoService_StreamUserTodoItemsWithHashServer{ctx: ctx}
+
+	err := server.StreamUserTodoItemsWithHash(&StreamUserTodoItemsWithHashRequest{UserID: 1, MaxWorkers: 1, Ordered: true}, stream)
+
+	if code := status.Code(err); code != codes.DeadlineExceeded {
+		t.Errorf("StreamUserTodoItemsWithHash() got code %v, want %v", code, codes.DeadlineExceeded)
+	}
+	if got := len(stream.Results); got == 0 || got == 3 {
+		t.Errorf("StreamUserTodoItemsWithHash() sent %d items before the deadline, want some but not all", got)
+	}
+	for i, item := range stream.Results {
+		if item.Item.TodoID != int32(i+1) {
+			t.Errorf("StreamUserTodoItemsWithHash() sent todo %d at position %d, want todo %d", item.Item.TodoID, i, i+1)
+		}
+	}
+}
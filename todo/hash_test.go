@@ -0,0 +1,148 @@
+package todo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"hash/fnv"
+	"testing"
+	"time"
+	"todo-app/models"
+
+	"github.com/cespare/xxhash/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//hashed returns the item with its hash by the default algorithm
+func hashed(item *TodoItem) *TodoItemWithHash {
+	fn, _ := lookupHash(DefaultHashAlgorithm)
+	return &TodoItemWithHash{Item: item, Hash: fn(canonicalTodo(item)), Algorithm: DefaultHashAlgorithm}
+}
+
+func TestCanonicalTodo(t *testing.T) {
+	testData := []struct {
+		desc  string
+		input *TodoItem
+		want  string
+	}{
+		{
+			desc:  "empty item",
+			input: &TodoItem{},
+			want:  "00000000" + "00000000" + "00000000" + "00" + "00" + "00" + "00000000" + "00" + "00",
+		},
+		{
+			desc:  "all fields",
+			input: &TodoItem{TodoID: 1, UserID: 2, Todo: "ab", Completed: true, CompletedAt: &timestamppb.Timestamp{Seconds: 3, Nanos: 4}, Priority: Priority_PRIORITY_HIGH},
+			want:  "00000001" + "00000002" + "00000002" + "6162" + "01" + "01" + "0000000000000003" + "00000004" + "00" + "00000003" + "00" + "00",
+		},
+	}
+
+	for _, tc := range testData {
+		if got := hex.EncodeToString(canonicalTodo(tc.input)); got != tc.want {
+			t.Errorf("[%q]: canonicalTodo() got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestHashAlgorithms(t *testing.T) {
+	canonical := canonicalTodo(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"})
+	fnv1a := fnv.New64a()
+	fnv1a.Write(canonical)
+	digest := sha256.Sum256(canonical)
+
+	testData := []struct {
+		name string
+		want uint64
+	}{
+		{name: "fnv1a", want: fnv1a.Sum64()},
+		{name: "xxhash", want: xxhash.Sum64(canonical)},
+		{name: "sha256", want: binary.BigEndian.Uint64(digest[:8])},
+		{name: "crc32", want: uint64(crc32.ChecksumIEEE(canonical))},
+	}
+
+	for _, tc := range testData {
+		fn, ok := lookupHash(tc.name)
+		if !ok {
+			t.Errorf("[%q]: algorithm isn't registered", tc.name)
+			continue
+		}
+		if got := fn(canonical); got != tc.want {
+			t.Errorf("[%q]: hash got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHashDetectsChanges(t *testing.T) {
+	item := &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}
+	changed := []*TodoItem{
+		{TodoID: 1, UserID: 1, Todo: "Task 2"},
+		{TodoID: 1, UserID: 1, Todo: "Task 1", Completed: true},
+		{TodoID: 1, UserID: 1, Todo: "Task 1", DueDate: timestamppb.New(time.Unix(0, 0))},
+		//the old hash only added the ids
+		{TodoID: 2, UserID: 0, Todo: "Task 1"},
+	}
+
+	for _, name := range HashAlgorithms() {
+		fn, _ := lookupHash(name)
+		for _, other := range changed {
+			if fn(canonicalTodo(item)) == fn(canonicalTodo(other)) {
+				t.Errorf("[%q]: %v and %v got the same hash", name, item, other)
+			}
+		}
+	}
+}
+
+func TestRegisterHash(t *testing.T) {
+	RegisterHash("length", func(canonical []byte) uint64 { return uint64(len(canonical)) })
+	defer func() {
+		hashMu.Lock()
+		delete(hashFuncs, "length")
+		hashMu.Unlock()
+	}()
+
+	fakeDS := testingDB{data: []*models.TodoItem{&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}}}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	resp, err := server.GetUserTodoItemsWithHash(context.Background(), &GetUserTodoItemsWithHashRequest{UserID: 1, Algorithm: "length"})
+	if err != nil {
+		t.Fatalf("GetUserTodoItemsWithHash() got error %v", err)
+	}
+	if got := resp.Items[0]; got.Algorithm != "length" || got.Hash != uint64(len(canonicalTodo(got.Item))) {
+		t.Errorf("GetUserTodoItemsWithHash() got hash %d by %q, want the registered algorithm", got.Hash, got.Algorithm)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterHash() of a taken name didn't panic")
+		}
+	}()
+	RegisterHash("fnv1a", nil)
+}
+
+func TestHasher(t *testing.T) {
+	testData := []struct {
+		desc      string
+		server    string
+		request   string
+		wantName  string
+		wantError codes.Code
+	}{
+		{desc: "default", server: "", request: "", wantName: DefaultHashAlgorithm, wantError: codes.OK},
+		{desc: "server default", server: "crc32", request: "", wantName: "crc32", wantError: codes.OK},
+		{desc: "request overrides server", server: "crc32", request: "xxhash", wantName: "xxhash", wantError: codes.OK},
+		{desc: "unknown", server: "", request: "md5", wantName: "", wantError: codes.InvalidArgument},
+	}
+
+	for _, tc := range testData {
+		server := Server{HashAlgorithm: tc.server}
+
+		h, err := server.hasher(tc.request)
+
+		if code := status.Code(err); code != tc.wantError || h.name != tc.wantName {
+			t.Errorf("[%q]: hasher() got %q and code %v, want %q and %v", tc.desc, h.name, code, tc.wantName, tc.wantError)
+		}
+	}
+}
@@ -1,13 +1,68 @@
 package todo
 
 import (
+	"time"
 	"todo-app/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+//toModelsTodoItem uses the getters so a missing item maps to an empty one
 func toModelsTodoItem(item *TodoItem) *models.TodoItem {
-	return &models.TodoItem{TodoID: item.TodoID, UserID: item.UserID, Todo: item.Todo}
+	return &models.TodoItem{
+		TodoID:      item.GetTodoID(),
+		UserID:      item.GetUserID(),
+		Todo:        item.GetTodo(),
+		Completed:   item.GetCompleted(),
+		CompletedAt: toModelsTimePointer(item.GetCompletedAt()),
+		DueDate:     toModelsTimePointer(item.GetDueDate()),
+		Priority:    models.Priority(item.GetPriority()),
+		CreatedAt:   toModelsTime(item.GetCreatedAt()),
+		UpdatedAt:   toModelsTime(item.GetUpdatedAt()),
+	}
 }
 
 func toProtoTodoItem(item *models.TodoItem) *TodoItem {
-	return &TodoItem{TodoID: item.TodoID, UserID: item.UserID, Todo: item.Todo}
+	return &TodoItem{
+		TodoID:      item.TodoID,
+		UserID:      item.UserID,
+		Todo:        item.Todo,
+		Completed:   item.Completed,
+		CompletedAt: toProtoTimestampPointer(item.CompletedAt),
+		DueDate:     toProtoTimestampPointer(item.DueDate),
+		Priority:    Priority(item.Priority),
+		CreatedAt:   toProtoTimestamp(item.CreatedAt),
+		UpdatedAt:   toProtoTimestamp(item.UpdatedAt),
+	}
+}
+
+//toModelsTime maps a missing timestamp to the zero time
+func toModelsTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toModelsTimePointer(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+//toProtoTimestamp maps the zero time to a missing timestamp
+func toProtoTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toProtoTimestampPointer(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
 }
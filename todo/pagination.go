@@ -0,0 +1,72 @@
+package todo
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"todo-app/models"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+
+	pageTokenPrefix = "todo:"
+)
+
+//page is a keyset cursor, items with TodoID greater than AfterID are returned
+type page struct {
+	afterID int32
+	size    int
+}
+
+//encodePageToken returns an opaque token resuming after the given todo id
+func encodePageToken(lastID int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(pageTokenPrefix + strconv.FormatInt(int64(lastID), 10)))
+}
+
+//decodePageToken returns the todo id of a page token or stream cursor, field names it in errors
+func decodePageToken(field string, token string) (int32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), pageTokenPrefix) {
+		return 0, invalidArgument(field, "invalid token %q", token)
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(string(raw), pageTokenPrefix), 10, 32)
+	if err != nil || id < 0 {
+		return 0, invalidArgument(field, "invalid token %q", token)
+	}
+	return int32(id), nil
+}
+
+//toPage validates the page size and token of a list request
+//a zero page size uses defaultPageSize, larger sizes are capped at maxPageSize
+func toPage(pageSize int32, pageToken string) (page, error) {
+	if pageSize < 0 {
+		return page{}, invalidArgument("pageSize", "must not be negative, got %d", pageSize)
+	}
+	p := page{size: int(pageSize)}
+	if p.size == 0 {
+		p.size = defaultPageSize
+	}
+	if p.size > maxPageSize {
+		p.size = maxPageSize
+	}
+	if pageToken != "" {
+		afterID, err := decodePageToken("pageToken", pageToken)
+		if err != nil {
+			return page{}, err
+		}
+		p.afterID = afterID
+	}
+	return p, nil
+}
+
+//nextPage trims the extra item fetched past the page size
+//and returns the token of the next page, empty when this is the last page
+func nextPage(todos []*models.TodoItem, p page) ([]*models.TodoItem, string) {
+	if len(todos) <= p.size {
+		return todos, ""
+	}
+	todos = todos[:p.size]
+	return todos, encodePageToken(todos[p.size-1].TodoID)
+}
@@ -0,0 +1,36 @@
+package todo
+
+import "context"
+
+//Pool is a fixed number of slots shared by concurrent requests, a nil Pool has unlimited slots
+type Pool struct {
+	slots chan struct{}
+}
+
+//NewPool returns a pool of size slots, or nil for an unbounded pool when size isn't positive
+func NewPool(size int) *Pool {
+	if size <= 0 {
+		return nil
+	}
+	return &Pool{slots: make(chan struct{}, size)}
+}
+
+//acquire waits for a free slot until ctx is done
+func (this *Pool) acquire(ctx context.Context) error {
+	if this == nil {
+		return nil
+	}
+	select {
+	case this.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (this *Pool) release() {
+	if this == nil {
+		return
+	}
+	<-this.slots
+}
@@ -0,0 +1,102 @@
+package todo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+//concurrency counts the functions running at the same time
+type concurrency struct {
+	mu      sync.Mutex
+	running int
+	max     int
+}
+
+func (this *concurrency) task(context.Context) error {
+	this.mu.Lock()
+	this.running++
+	if this.running > this.max {
+		this.max = this.running
+	}
+	this.mu.Unlock()
+	time.Sleep(2 * time.Millisecond)
+	this.mu.Lock()
+	this.running--
+	this.mu.Unlock()
+	return nil
+}
+
+func TestParallelWorkers(t *testing.T) {
+	testData := []struct {
+		desc         string
+		serverLimit  int
+		requestLimit int32
+		pool         int
+		wantMax      int
+	}{
+		{desc: "unbounded", serverLimit: 0, requestLimit: 0, pool: 0, wantMax: 20},
+		{desc: "server limit", serverLimit: 4, requestLimit: 0, pool: 0, wantMax: 4},
+		{desc: "request lowers the limit", serverLimit: 4, requestLimit: 2, pool: 0, wantMax: 2},
+		{desc: "request can't raise the limit", serverLimit: 4, requestLimit: 10, pool: 0, wantMax: 4},
+		{desc: "shared pool", serverLimit: 8, requestLimit: 0, pool: 3, wantMax: 3},
+	}
+
+	for _, tc := range testData {
+		s := &Server{MaxWorkers: tc.serverLimit, Pool: NewPool(tc.pool)}
+		var c concurrency
+		list := make([]func(context.Context) error, 20)
+		for i := range list {
+			list[i] = c.task
+		}
+
+		err := s.parallel(context.Background(), tc.requestLimit, list)
+
+		if err != nil {
+			t.Errorf("[%q]: parallel() got error %v, want success", tc.desc, err)
+		}
+		//the unbounded case may not start all goroutines at once on a busy machine
+		if c.max > tc.wantMax || (tc.wantMax < len(list) && c.max != tc.wantMax) {
+			t.Errorf("[%q]: parallel() ran %d functions at once, want %d", tc.desc, c.max, tc.wantMax)
+		}
+	}
+}
+
+func TestParallelSharedPool(t *testing.T) {
+	s := &Server{MaxWorkers: 4, Pool: NewPool(4)}
+	var c concurrency
+	var wg sync.WaitGroup
+	for r := 0; r < 3; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			list := make([]func(context.Context) error, 10)
+			for i := range list {
+				list[i] = c.task
+			}
+			s.parallel(context.Background(), 0, list)
+		}()
+	}
+	wg.Wait()
+
+	if c.max > 4 {
+		t.Errorf("parallel() ran %d functions of concurrent requests at once, want at most 4", c.max)
+	}
+}
+
+func TestParallelCanceledWhileWaiting(t *testing.T) {
+	pool := NewPool(1)
+	pool.acquire(context.Background())
+	defer pool.release()
+	s := &Server{Pool: pool}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	calls := 0
+
+	err := s.parallel(ctx, 0, []func(context.Context) error{func(context.Context) error { calls++; return nil }})
+
+	if err != context.DeadlineExceeded || calls != 0 {
+		t.Errorf("parallel() with a full pool got error %v and %d calls, want DeadlineExceeded and no call", err, calls)
+	}
+}
@@ -4,481 +4,494 @@ import (
 	"context"
 	"errors"
 	"io"
-	"log"
-	sync "sync"
 	"time"
+	"todo-app/logging"
 	"todo-app/models"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
-const mod = 291391
+//tracer starts the spans of the hash fan-out, it does nothing until a tracer provider is installed
+var tracer = otel.Tracer("todo-app/todo")
 
 //DataStore defining functions to be implemented to store user todos
+//DataStore methods stop their work and return the context error when ctx is canceled or its deadline expires
 type DataStore interface {
-	InsertTodoItem(item *models.TodoItem) (int32, error)
-	GetAllTodos() ([]*models.TodoItem, error)
-	GetUserTodos(userID int32) ([]*models.TodoItem, error)
-	DeleteUserTodos(userID int32) error
-	Truncate() error
+	InsertTodoItem(ctx context.Context, item *models.TodoItem) (int32, error)
+	GetAllTodos(ctx context.Context) ([]*models.TodoItem, error)
+	GetUserTodos(ctx context.Context, userID int32) ([]*models.TodoItem, error)
+	GetAllTodosPage(ctx context.Context, afterID int32, limit int) ([]*models.TodoItem, error)
+	GetUserTodosPage(ctx context.Context, userID int32, afterID int32, limit int) ([]*models.TodoItem, error)
+	DeleteUserTodos(ctx context.Context, userID int32) error
+	GetTodoItem(ctx context.Context, todoID int32) (*models.TodoItem, error)
+	UpdateTodoItem(ctx context.Context, item *models.TodoItem) error
+	SetTodoCompleted(ctx context.Context, todoID int32, completed bool) error
+	//SetTodoDueDate sets the due date of the todo item, nil clears it
+	SetTodoDueDate(ctx context.Context, todoID int32, dueDate *time.Time) error
+	SetTodoPriority(ctx context.Context, todoID int32, priority models.Priority) error
+	DeleteTodoItem(ctx context.Context, todoID int32) error
+	Truncate(ctx context.Context) error
+	//GetUserTodosVersion returns a number that changes with every write to the todos of the user
+	//versions follow the clock so they don't repeat after a restart or a restore of the data store
+	//all servers sharing the data store see the same version, it is the etag of user todos pages
+	GetUserTodosVersion(ctx context.Context, userID int32) (int64, error)
+	//Ping checks the data store can be reached, it is used for health checking
+	Ping(ctx context.Context) error
+	//Close releases the resources of the data store, it is called once on server shutdown
+	Close() error
 }
 
 //Server implementing TodoSeviceServer
 type Server struct {
 	DS          DataStore
 	WaitingTime time.Duration
+	//RequireAuth rejects calls without a caller identity in the context
+	RequireAuth bool
+	//HashAlgorithm is used when a request doesn't choose one, empty is DefaultHashAlgorithm
+	HashAlgorithm string
+	//Transform is the strategy used when a request doesn't choose one, empty is DefaultTransform
+	Transform string
+	//Transforms observes the strategies run by hash requests, it may be nil
+	Transforms TransformObserver
+	//FanOut observes the goroutines started to hash todo items, it may be nil
+	FanOut FanOutObserver
+	//MaxWorkers caps the goroutines hashing the items of one request, zero is one goroutine per item
+	MaxWorkers int
+	//Pool caps the hash goroutines of all requests together, nil is unbounded
+	Pool *Pool
+
+	watchers hub
+}
+
+//FanOutObserver is told how many goroutines a request starts, the returned function is called when they finished
+type FanOutObserver interface {
+	FanOut(n int) (done func())
 }
 
 func (s *Server) mustEmbedUnimplementedTodoServiceServer() {}
 
 //AddTodo function to add todoitem to database
 func (s *Server) AddTodo(ctx context.Context, message *AddTodoRequest) (*AddTodoResponse, error) {
-	log.Printf("Received : %v", message)
-	item := message.GetItem()
-	id, err := s.DS.InsertTodoItem(toModelsTodoItem(item))
-	if err != nil {
+	logging.FromContext(ctx).Debug("received add todo request", "request", message)
+	item := toModelsTodoItem(message.GetItem())
+	if err := s.authorizeUser(ctx, item.UserID); err != nil {
 		return nil, err
 	}
+	id, err := s.DS.InsertTodoItem(ctx, item)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
 	item.TodoID = id
-	return &AddTodoResponse{Item: item}, nil
+	response := &AddTodoResponse{Item: toProtoTodoItem(item)}
+	s.watchers.publish(EventType_EVENT_TYPE_CREATED, response.Item)
+	return response, nil
 }
 
-//GetAllTodos function to get all todos from database
-func (s *Server) GetAllTodos(ctx context.Context, message *NoParams) (*GetAllTodosResponse, error) {
-	log.Printf("Received Get all todos request")
+//GetAllTodos function to get a page of all todos from database ordered by todo id
+func (s *Server) GetAllTodos(ctx context.Context, message *GetAllTodosRequest) (*GetAllTodosResponse, error) {
+	logging.FromContext(ctx).Debug("received get all todos request", "request", message)
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	p, err := toPage(message.PageSize, message.PageToken)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
 	response := GetAllTodosResponse{Items: make([]*TodoItem, 0)}
-	todos, err := s.DS.GetAllTodos()
+	todos, err := s.DS.GetAllTodosPage(ctx, p.afterID, p.size+1)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
+	todos, response.NextPageToken = nextPage(todos, p)
 	for _, todo := range todos {
 		response.Items = append(response.Items, toProtoTodoItem(todo))
 	}
 	return &response, nil
 }
 
-//GetAllTodosStreaming function to get all todos from database
-//server side streaming
+//GetAllTodosStreaming function to get all todos from database ordered by todo id
+//server side streaming, the messages are those of the original stream so existing clients keep working
 func (s *Server) GetAllTodosStreaming(message *NoParams, stream TodoService_GetAllTodosStreamingServer) error {
-	log.Printf("Received Get all todos streaming request")
-	todos, err := s.DS.GetAllTodos()
-	if err != nil {
+	ctx := stream.Context()
+	logging.FromContext(ctx).Debug("received get all todos streaming request", "request", message)
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return err
+	}
+	return s.streamAllTodos(ctx, 0, func(todo *models.TodoItem) error {
+		return stream.Send(toProtoTodoItem(todo))
+	})
+}
+
+//GetAllTodosStreamingWithCursor function to get all todos from database ordered by todo id
+//server side streaming, each item carries a cursor the stream can be resumed after
+func (s *Server) GetAllTodosStreamingWithCursor(message *GetAllTodosStreamingRequest, stream TodoService_GetAllTodosStreamingWithCursorServer) error {
+	ctx := stream.Context()
+	logging.FromContext(ctx).Debug("received get all todos streaming with cursor request", "request", message)
+	if err := s.authorizeAdmin(ctx); err != nil {
 		return err
 	}
+	var afterID int32
+	if message.ResumeAfter != "" {
+		var err error
+		afterID, err = decodePageToken("resumeAfter", message.ResumeAfter)
+		if err != nil {
+			return err
+		}
+	}
+	return s.streamAllTodos(ctx, afterID, func(todo *models.TodoItem) error {
+		return stream.Send(&GetAllTodosStreamingResponse{Item: toProtoTodoItem(todo), Cursor: encodePageToken(todo.TodoID)})
+	})
+}
+
+//streamAllTodos sends the todos after afterID one per waiting time
+func (s *Server) streamAllTodos(ctx context.Context, afterID int32, send func(*models.TodoItem) error) error {
 	ticker := time.NewTicker(s.WaitingTime)
 	defer ticker.Stop()
-	for _, todo := range todos {
-		select {
-		case <-ticker.C:
-			err := stream.Send(toProtoTodoItem(todo))
-			if err != nil {
-				return err
+	//todos are read page by page so large exports don't load the whole table
+	for {
+		todos, err := s.DS.GetAllTodosPage(ctx, afterID, defaultPageSize)
+		if err != nil {
+			return toStatusError(ctx, err)
+		}
+		for _, todo := range todos {
+			select {
+			case <-ticker.C:
+				if err := send(todo); err != nil {
+					return toStatusError(ctx, err)
+				}
+			//client canceled or server stopped
+			case <-ctx.Done():
+				return toStatusError(ctx, ctx.Err())
 			}
+			afterID = todo.TodoID
+		}
+		if len(todos) < defaultPageSize {
+			return nil
 		}
 	}
-	return nil
 }
 
 //GetUserTodos function to get a stream of user ids and return a stream of todoitems
 func (s *Server) GetUserTodos(stream TodoService_GetUserTodosServer) error {
-	log.Println("Received get user todos request")
+	ctx := stream.Context()
+	logger := logging.FromContext(ctx)
+	logger.Debug("received get user todos request")
 	ticker := time.NewTicker(s.WaitingTime)
 	defer ticker.Stop()
 	for {
 		message, err := stream.Recv()
 		if err == io.EOF {
-			log.Println("Finished get user todos request")
+			logger.Debug("finished get user todos request")
 			return nil
 		}
 		if err != nil {
-			log.Printf("Error receiving from client %s", err)
-			return err
+			logger.Warn("error receiving from client", "error", err)
+			return toStatusError(ctx, err)
 		}
-		log.Println("Received", message)
+		logger.Debug("received", "request", message)
 		userID := message.UserID
+		if err := s.authorizeUser(ctx, userID); err != nil {
+			return toStatusError(ctx, err)
+		}
+		p, err := toPage(message.PageSize, message.PageToken)
+		if err != nil {
+			return toStatusError(ctx, err)
+		}
 		select {
 		case <-ticker.C:
 
-			dbTodos, err := s.DS.GetUserTodos(userID)
+			//the tag is taken before the page is read, a write in between only makes the next request send the page again
+			version, err := s.DS.GetUserTodosVersion(ctx, userID)
 			if err != nil {
-				return err
+				return toStatusError(ctx, err)
 			}
+			etag := pageTag(version, p)
+			if message.GetIfNoneMatch() == etag {
+				response := &GetUserTodosResponse{Etag: etag, NotModified: true}
+				logger.Debug("sending", "response", response)
+				stream.Send(response)
+				continue
+			}
+			dbTodos, err := s.DS.GetUserTodosPage(ctx, userID, p.afterID, p.size+1)
+			if err != nil {
+				return toStatusError(ctx, err)
+			}
+			dbTodos, nextPageToken := nextPage(dbTodos, p)
 			var todos []*TodoItem
 			for _, todo := range dbTodos {
 				todos = append(todos, toProtoTodoItem(todo))
 			}
-			response := &GetUserTodosResponse{Items: todos}
-			log.Println("Sending", response)
+			response := &GetUserTodosResponse{Items: todos, NextPageToken: nextPageToken, Etag: etag}
+			logger.Debug("sending", "response", response)
 			stream.Send(response)
+		//client canceled or server stopped
+		case <-ctx.Done():
+			return toStatusError(ctx, ctx.Err())
 		}
 	}
 }
 
-//DeleteUserTodos input user id, delete user todos from datastore
-func (s *Server) DeleteUserTodos(ctx context.Context, message *DeleteUserTodosRequest) (*DeleteUserTodosResponse, error) {
-	userID := message.UserID
-	err := s.DS.DeleteUserTodos(userID)
-	if err != nil {
+//GetTodo input todo id, return the todo item unless the client's copy matches its etag
+func (s *Server) GetTodo(ctx context.Context, message *GetTodoRequest) (*GetTodoResponse, error) {
+	logging.FromContext(ctx).Debug("received get todo request", "request", message)
+	todoID := message.TodoID
+	if err := s.authorizeTodo(ctx, todoID); err != nil {
 		return nil, err
 	}
-	return &DeleteUserTodosResponse{}, nil
-}
-
-func (s *Server) computeTodoHash(ctx context.Context, item *TodoItem) (int32, error) {
-	waitingTime := s.WaitingTime / 2
-	select {
-	case <-time.After(waitingTime):
-		return (item.TodoID + item.UserID) % mod, nil
-	//context timed out or canceld
-	case <-ctx.Done():
-		return 0, errors.New("Canceld or Timed out")
+	stored, err := s.DS.GetTodoItem(ctx, todoID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, notFound(todoID)
 	}
-}
-
-func parallel(ctx context.Context, list []func(context.Context) error) error {
-	group, childContext := errgroup.WithContext(ctx)
-	for _, f := range list {
-		f := f
-		group.Go(func() error {
-			err := f(childContext)
-			return err
-		})
-	}
-	return group.Wait()
-}
-
-func (s *Server) transformTodos(ctx context.Context, todos []*models.TodoItem, process func(context.Context, *TodoItem) (int32, error)) ([]*TodoItemWithHash, error) {
-
-	response := make([]*TodoItemWithHash, len(todos))
-
-	f := func(ctx context.Context, idx int, item *TodoItem) error {
-		hash, err := process(ctx, item)
-		response[idx] = &TodoItemWithHash{Item: item, Hash: hash}
-		return err
-	}
-
-	var list []func(context.Context) error
-	for idx, todo := range todos {
-		idx := idx
-		todo := todo
-		list = append(list, func(ctx context.Context) error {
-			return f(ctx, idx, toProtoTodoItem(todo))
-		})
-	}
-
-	err := parallel(ctx, list)
-
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
-	return response, nil
+	item := toProtoTodoItem(stored)
+	etag := itemTag(item)
+	if message.GetIfNoneMatch() == etag {
+		return &GetTodoResponse{Etag: etag, NotModified: true}, nil
+	}
+	return &GetTodoResponse{Item: item, Etag: etag}, nil
 }
 
-func (s *Server) GetUserTodoItemsWithHash(ctx context.Context, message *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
-
-	log.Println("Received get user todo items with hash request", message)
-
+//DeleteUserTodos input user id, delete user todos from datastore
+func (s *Server) DeleteUserTodos(ctx context.Context, message *DeleteUserTodosRequest) (*DeleteUserTodosResponse, error) {
 	userID := message.UserID
-	todos, err := s.DS.GetUserTodos(userID)
-
-	if err != nil {
+	if err := s.authorizeUser(ctx, userID); err != nil {
 		return nil, err
 	}
-
-	response := &GetUserTodoItemsWithHashResponse{}
-
-	items, err := s.transformTodos(ctx, todos, s.computeTodoHash)
+	//the deleted items are read first for the watchers, items added meanwhile may be deleted without event
+	var deleted []*models.TodoItem
+	if s.watchers.watching(userID) {
+		deleted, _ = s.DS.GetUserTodos(ctx, userID)
+	}
+	err := s.DS.DeleteUserTodos(ctx, userID)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
-	response.Items = items
-
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("Timed out")
-	default:
-		return response, nil
+	for _, item := range deleted {
+		s.watchers.publish(EventType_EVENT_TYPE_DELETED, toProtoTodoItem(item))
 	}
+	return &DeleteUserTodosResponse{}, nil
 }
 
-func (s *Server) transformTodosPointer(ctx context.Context, todos []*models.TodoItem, process func(context.Context, *TodoItem) (int32, error)) ([]*TodoItemWithHash, error) {
-
-	response := make([]*TodoItemWithHash, len(todos))
-
-	f := func(ctx context.Context, item *TodoItemWithHash) error {
-		hash, err := process(ctx, item.Item)
-		item.Hash = hash
-		return err
+//UpdateTodo input todo item, update the text of the item with the same todo id
+func (s *Server) UpdateTodo(ctx context.Context, message *UpdateTodoRequest) (*UpdateTodoResponse, error) {
+	logging.FromContext(ctx).Debug("received update todo request", "request", message)
+	item := message.GetItem()
+	if err := s.authorizeTodo(ctx, item.GetTodoID()); err != nil {
+		return nil, err
 	}
-
-	var list []func(context.Context) error
-	for idx, todo := range todos {
-		idx := idx
-		response[idx] = &TodoItemWithHash{Item: toProtoTodoItem(todo)}
-		list = append(list, func(ctx context.Context) error {
-			return f(ctx, response[idx])
-		})
+	err := s.DS.UpdateTodoItem(ctx, toModelsTodoItem(item))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, notFound(item.TodoID)
 	}
-
-	err := parallel(ctx, list)
-
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
-	return response, nil
-}
-
-func (s *Server) GetUserTodoItemsWithHashPointer(ctx context.Context, message *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
-
-	log.Println("Received get user todo items with hash request", message)
-
-	userID := message.UserID
-	todos, err := s.DS.GetUserTodos(userID)
-
-	if err != nil {
-		return nil, err
+	//the stored item carries the timestamps and completion state the request doesn't set
+	updated, err := s.publishStored(ctx, EventType_EVENT_TYPE_UPDATED, item.TodoID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, notFound(item.TodoID)
 	}
-
-	response := &GetUserTodoItemsWithHashResponse{}
-
-	items, err := s.transformTodosPointer(ctx, todos, s.computeTodoHash)
 	if err != nil {
-		return nil, err
-	}
-	response.Items = items
-
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("Timed out")
-	default:
-		return response, nil
+		return nil, toStatusError(ctx, err)
 	}
+	return &UpdateTodoResponse{Item: updated}, nil
 }
 
-func (s *Server) transformTodosAppend(ctx context.Context, todos []*models.TodoItem, process func(context.Context, *TodoItem) (int32, error)) ([]*TodoItemWithHash, error) {
-
-	mu := sync.Mutex{}
-	var response []*TodoItemWithHash
-
-	f := func(ctx context.Context, item *TodoItem) error {
-		hash, err := process(ctx, item)
-		mu.Lock()
-		response = append(response, &TodoItemWithHash{Item: item, Hash: hash})
-		mu.Unlock()
-		return err
+//DeleteTodo input todo id, delete the todo item from datastore
+func (s *Server) DeleteTodo(ctx context.Context, message *DeleteTodoRequest) (*DeleteTodoResponse, error) {
+	todoID := message.TodoID
+	if err := s.authorizeTodo(ctx, todoID); err != nil {
+		return nil, err
 	}
-
-	var list []func(context.Context) error
-	for _, todo := range todos {
-		todo := todo
-		list = append(list, func(ctx context.Context) error {
-			return f(ctx, toProtoTodoItem(todo))
-		})
+	//the item is read first to tell the watchers of its user
+	var deleted *models.TodoItem
+	if s.watchers.active() {
+		deleted, _ = s.DS.GetTodoItem(ctx, todoID)
+	}
+	err := s.DS.DeleteTodoItem(ctx, todoID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, notFound(todoID)
 	}
-
-	err := parallel(ctx, list)
-
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
-	return response, nil
+	if deleted != nil {
+		s.watchers.publish(EventType_EVENT_TYPE_DELETED, toProtoTodoItem(deleted))
+	}
+	return &DeleteTodoResponse{}, nil
 }
 
-func (s *Server) GetUserTodoItemsWithHashAppend(ctx context.Context, message *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
-
-	log.Println("Received get user todo items with hash request", message)
-
-	userID := message.UserID
-	todos, err := s.DS.GetUserTodos(userID)
-
+//CompleteTodo input todo id, mark the todo item as completed
+func (s *Server) CompleteTodo(ctx context.Context, message *CompleteTodoRequest) (*CompleteTodoResponse, error) {
+	item, err := s.setCompleted(ctx, message.TodoID, true)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
+	return &CompleteTodoResponse{Item: item}, nil
+}
 
-	response := &GetUserTodoItemsWithHashResponse{}
-
-	items, err := s.transformTodosAppend(ctx, todos, s.computeTodoHash)
+//ReopenTodo input todo id, mark the todo item as not completed
+func (s *Server) ReopenTodo(ctx context.Context, message *ReopenTodoRequest) (*ReopenTodoResponse, error) {
+	item, err := s.setCompleted(ctx, message.TodoID, false)
 	if err != nil {
-		return nil, err
-	}
-	response.Items = items
-
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("Timed out")
-	default:
-		return response, nil
+		return nil, toStatusError(ctx, err)
 	}
+	return &ReopenTodoResponse{Item: item}, nil
 }
 
-func (s *Server) transformTodosAppendPreAllocation(ctx context.Context, todos []*models.TodoItem, process func(context.Context, *TodoItem) (int32, error)) ([]*TodoItemWithHash, error) {
-
-	mu := sync.Mutex{}
-	response := make([]*TodoItemWithHash, 0, len(todos))
-
-	f := func(ctx context.Context, item *TodoItem) error {
-		hash, err := process(ctx, item)
-		mu.Lock()
-		response = append(response, &TodoItemWithHash{Item: item, Hash: hash})
-		mu.Unlock()
-		return err
-	}
-
-	var list []func(context.Context) error
-	for _, todo := range todos {
-		todo := todo
-		list = append(list, func(ctx context.Context) error {
-			return f(ctx, toProtoTodoItem(todo))
-		})
-	}
-
-	err := parallel(ctx, list)
-
+//SetTodoDueDate input todo id and due date, set or clear the due date of the todo item
+func (s *Server) SetTodoDueDate(ctx context.Context, message *SetTodoDueDateRequest) (*SetTodoDueDateResponse, error) {
+	item, err := s.setField(ctx, message.TodoID, func() error {
+		return s.DS.SetTodoDueDate(ctx, message.TodoID, toModelsTimePointer(message.DueDate))
+	})
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
-	return response, nil
+	return &SetTodoDueDateResponse{Item: item}, nil
 }
 
-func (s *Server) GetUserTodoItemsWithHashAppendPreAllocation(ctx context.Context, message *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
-
-	log.Println("Received get user todo items with hash request", message)
-
-	userID := message.UserID
-	todos, err := s.DS.GetUserTodos(userID)
-
+//SetTodoPriority input todo id and priority, set the priority of the todo item
+func (s *Server) SetTodoPriority(ctx context.Context, message *SetTodoPriorityRequest) (*SetTodoPriorityResponse, error) {
+	item, err := s.setField(ctx, message.TodoID, func() error {
+		return s.DS.SetTodoPriority(ctx, message.TodoID, models.Priority(message.Priority))
+	})
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
+	return &SetTodoPriorityResponse{Item: item}, nil
+}
 
-	response := &GetUserTodoItemsWithHashResponse{}
+func (s *Server) setCompleted(ctx context.Context, todoID int32, completed bool) (*TodoItem, error) {
+	return s.setField(ctx, todoID, func() error {
+		return s.DS.SetTodoCompleted(ctx, todoID, completed)
+	})
+}
 
-	items, err := s.transformTodosAppendPreAllocation(ctx, todos, s.computeTodoHash)
-	if err != nil {
+//setField runs the write of one field of the todo item and returns the stored item
+func (s *Server) setField(ctx context.Context, todoID int32, write func() error) (*TodoItem, error) {
+	if err := s.authorizeTodo(ctx, todoID); err != nil {
 		return nil, err
 	}
-	response.Items = items
+	err := write()
+	if err == nil {
+		var item *models.TodoItem
+		item, err = s.DS.GetTodoItem(ctx, todoID)
+		if err == nil {
+			updated := toProtoTodoItem(item)
+			s.watchers.publish(EventType_EVENT_TYPE_UPDATED, updated)
+			return updated, nil
+		}
+	}
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, notFound(todoID)
+	}
+	return nil, err
+}
 
+//computeTodoHash takes WaitingTime/2 to simulate an expensive hash
+func (s *Server) computeTodoHash(ctx context.Context, h itemHasher, item *TodoItem) (uint64, error) {
+	_, span := tracer.Start(ctx, "computeTodoHash", trace.WithAttributes(attribute.Int("todo.id", int(item.TodoID)), attribute.String("todo.hash_algorithm", h.name)))
+	defer span.End()
+	waitingTime := s.WaitingTime / 2
 	select {
+	case <-time.After(waitingTime):
+		return h.sum(canonicalTodo(item)), nil
+	//context timed out or canceld
 	case <-ctx.Done():
-		return nil, errors.New("Timed out")
-	default:
-		return response, nil
+		span.SetStatus(codes.Error, ctx.Err().Error())
+		return 0, ctx.Err()
 	}
 }
 
-func (s *Server) transformTodosAppendChannels(ctx context.Context, todos []*models.TodoItem, process func(context.Context, *TodoItem) (int32, error)) ([]*TodoItemWithHash, error) {
-
-	todoWithHashChannel := make(chan *TodoItemWithHash, len(todos))
-	var response []*TodoItemWithHash
-
-	f := func(ctx context.Context, item *TodoItem, ch chan *TodoItemWithHash) error {
-		hash, err := process(ctx, item)
-		ch <- &TodoItemWithHash{Item: item, Hash: hash}
-		return err
+//hashWith returns the process function of the transformers hashing with h
+func (s *Server) hashWith(h itemHasher) ProcessFunc {
+	return func(ctx context.Context, item *TodoItem) (uint64, error) {
+		return s.computeTodoHash(ctx, h, item)
 	}
+}
 
-	var list []func(context.Context) error
-	for _, todo := range todos {
-		todo := todo
-		list = append(list, func(ctx context.Context) error {
-			return f(ctx, toProtoTodoItem(todo), todoWithHashChannel)
+//parallel runs the functions on at most workers(maxWorkers) goroutines, each holding a slot of the shared pool
+//it stops starting functions when one fails or ctx is done
+func (s *Server) parallel(ctx context.Context, maxWorkers int32, list []func(context.Context) error) error {
+	workers := s.workers(maxWorkers, len(list))
+	if s.FanOut != nil {
+		done := s.FanOut.FanOut(workers)
+		defer done()
+	}
+	//the goroutines' spans are children of this one
+	ctx, span := tracer.Start(ctx, "parallel", trace.WithAttributes(attribute.Int("todo.goroutines", workers), attribute.Int("todo.items", len(list))))
+	defer span.End()
+	group, childContext := errgroup.WithContext(ctx)
+	group.SetLimit(workers)
+	var acquireErr error
+	for _, f := range list {
+		f := f
+		if acquireErr = s.Pool.acquire(childContext); acquireErr != nil {
+			break
+		}
+		group.Go(func() error {
+			defer s.Pool.release()
+			err := f(childContext)
+			return err
 		})
 	}
-
-	err := parallel(ctx, list)
-
-	for i := 0; i < len(todos); i++ {
-		response = append(response, <-todoWithHashChannel)
+	if err := group.Wait(); err != nil {
+		return err
 	}
+	return acquireErr
+}
 
-	if err != nil {
-		return nil, err
+//workers returns how many goroutines hash the n items of one request, the request may lower the server limit
+func (s *Server) workers(maxWorkers int32, n int) int {
+	workers := n
+	if s.MaxWorkers > 0 && s.MaxWorkers < workers {
+		workers = s.MaxWorkers
 	}
-	return response, nil
+	if maxWorkers > 0 && int(maxWorkers) < workers {
+		workers = int(maxWorkers)
+	}
+	return workers
 }
 
-func (s *Server) GetUserTodoItemsWithHashAppendChannels(ctx context.Context, message *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
+func (s *Server) GetUserTodoItemsWithHash(ctx context.Context, message *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
 
-	log.Println("Received get user todo items with hash request", message)
+	logging.FromContext(ctx).Debug("received get user todo items with hash request", "request", message)
 
 	userID := message.UserID
-	todos, err := s.DS.GetUserTodos(userID)
-
-	if err != nil {
+	if err := s.authorizeUser(ctx, userID); err != nil {
 		return nil, err
 	}
-
-	response := &GetUserTodoItemsWithHashResponse{}
-
-	items, err := s.transformTodosAppendChannels(ctx, todos, s.computeTodoHash)
+	h, err := s.hasher(message.GetAlgorithm())
 	if err != nil {
 		return nil, err
 	}
-	response.Items = items
-
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("Timed out")
-	default:
-		return response, nil
-	}
-}
-
-func (s *Server) transformTodosIndexingChannels(ctx context.Context, todos []*models.TodoItem, process func(context.Context, *TodoItem) (int32, error)) ([]*TodoItemWithHash, error) {
-
-	length := len(todos)
-	todoWithHashChannel := make(chan *TodoItemWithHash, length)
-	response := make([]*TodoItemWithHash, length)
-
-	f := func(ctx context.Context, item *TodoItem, ch chan *TodoItemWithHash) error {
-		hash, err := process(ctx, item)
-		ch <- &TodoItemWithHash{Item: item, Hash: hash}
-		return err
-	}
-
-	var list []func(context.Context) error
-	for _, todo := range todos {
-		todo := todo
-		list = append(list, func(ctx context.Context) error {
-			return f(ctx, toProtoTodoItem(todo), todoWithHashChannel)
-		})
-	}
-
-	err := parallel(ctx, list)
-
-	for i := 0; i < length; i++ {
-		response[i] = <-todoWithHashChannel
-	}
-
+	t, err := s.transformer(message.GetTransform())
 	if err != nil {
 		return nil, err
 	}
-	return response, nil
-}
-
-func (s *Server) GetUserTodoItemsWithHashIndexingChannels(ctx context.Context, message *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
-
-	log.Println("Received get user todo items with hash request", message)
-
-	userID := message.UserID
-	todos, err := s.DS.GetUserTodos(userID)
+	todos, err := s.DS.GetUserTodos(ctx, userID)
 
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
 
 	response := &GetUserTodoItemsWithHashResponse{}
 
-	items, err := s.transformTodosIndexingChannels(ctx, todos, s.computeTodoHash)
+	items, err := s.transform(ctx, t, todos, message.GetMaxWorkers(), s.hashWith(h))
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(ctx, err)
 	}
-	response.Items = items
+	response.Items = h.withAlgorithm(items)
 
 	select {
 	case <-ctx.Done():
-		return nil, errors.New("Timed out")
+		return nil, toStatusError(ctx, ctx.Err())
 	default:
 		return response, nil
 	}
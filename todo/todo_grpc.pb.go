@@ -18,11 +18,22 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TodoServiceClient interface {
 	AddTodo(ctx context.Context, in *AddTodoRequest, opts ...grpc.CallOption) (*AddTodoResponse, error)
-	GetAllTodos(ctx context.Context, in *NoParams, opts ...grpc.CallOption) (*GetAllTodosResponse, error)
+	GetAllTodos(ctx context.Context, in *GetAllTodosRequest, opts ...grpc.CallOption) (*GetAllTodosResponse, error)
+	// GetAllTodosStreaming keeps its original messages for existing clients, it can't be resumed
 	GetAllTodosStreaming(ctx context.Context, in *NoParams, opts ...grpc.CallOption) (TodoService_GetAllTodosStreamingClient, error)
+	GetAllTodosStreamingWithCursor(ctx context.Context, in *GetAllTodosStreamingRequest, opts ...grpc.CallOption) (TodoService_GetAllTodosStreamingWithCursorClient, error)
 	GetUserTodos(ctx context.Context, opts ...grpc.CallOption) (TodoService_GetUserTodosClient, error)
+	GetTodo(ctx context.Context, in *GetTodoRequest, opts ...grpc.CallOption) (*GetTodoResponse, error)
 	DeleteUserTodos(ctx context.Context, in *DeleteUserTodosRequest, opts ...grpc.CallOption) (*DeleteUserTodosResponse, error)
 	GetUserTodoItemsWithHash(ctx context.Context, in *GetUserTodoItemsWithHashRequest, opts ...grpc.CallOption) (*GetUserTodoItemsWithHashResponse, error)
+	StreamUserTodoItemsWithHash(ctx context.Context, in *StreamUserTodoItemsWithHashRequest, opts ...grpc.CallOption) (TodoService_StreamUserTodoItemsWithHashClient, error)
+	UpdateTodo(ctx context.Context, in *UpdateTodoRequest, opts ...grpc.CallOption) (*UpdateTodoResponse, error)
+	DeleteTodo(ctx context.Context, in *DeleteTodoRequest, opts ...grpc.CallOption) (*DeleteTodoResponse, error)
+	CompleteTodo(ctx context.Context, in *CompleteTodoRequest, opts ...grpc.CallOption) (*CompleteTodoResponse, error)
+	ReopenTodo(ctx context.Context, in *ReopenTodoRequest, opts ...grpc.CallOption) (*ReopenTodoResponse, error)
+	SetTodoDueDate(ctx context.Context, in *SetTodoDueDateRequest, opts ...grpc.CallOption) (*SetTodoDueDateResponse, error)
+	SetTodoPriority(ctx context.Context, in *SetTodoPriorityRequest, opts ...grpc.CallOption) (*SetTodoPriorityResponse, error)
+	WatchTodos(ctx context.Context, in *WatchTodosRequest, opts ...grpc.CallOption) (TodoService_WatchTodosClient, error)
 }
 
 type todoServiceClient struct {
@@ -42,7 +53,7 @@ func (c *todoServiceClient) AddTodo(ctx context.Context, in *AddTodoRequest, opt
 	return out, nil
 }
 
-func (c *todoServiceClient) GetAllTodos(ctx context.Context, in *NoParams, opts ...grpc.CallOption) (*GetAllTodosResponse, error) {
+func (c *todoServiceClient) GetAllTodos(ctx context.Context, in *GetAllTodosRequest, opts ...grpc.CallOption) (*GetAllTodosResponse, error) {
 	out := new(GetAllTodosResponse)
 	err := c.cc.Invoke(ctx, "/todo.TodoService/GetAllTodos", in, out, opts...)
 	if err != nil {
@@ -83,8 +94,40 @@ func (x *todoServiceGetAllTodosStreamingClient) Recv() (*TodoItem, error) {
 	return m, nil
 }
 
+func (c *todoServiceClient) GetAllTodosStreamingWithCursor(ctx context.Context, in *GetAllTodosStreamingRequest, opts ...grpc.CallOption) (TodoService_GetAllTodosStreamingWithCursorClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TodoService_ServiceDesc.Streams[1], "/todo.TodoService/GetAllTodosStreamingWithCursor", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoServiceGetAllTodosStreamingWithCursorClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TodoService_GetAllTodosStreamingWithCursorClient interface {
+	Recv() (*GetAllTodosStreamingResponse, error)
+	grpc.ClientStream
+}
+
+type todoServiceGetAllTodosStreamingWithCursorClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoServiceGetAllTodosStreamingWithCursorClient) Recv() (*GetAllTodosStreamingResponse, error) {
+	m := new(GetAllTodosStreamingResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *todoServiceClient) GetUserTodos(ctx context.Context, opts ...grpc.CallOption) (TodoService_GetUserTodosClient, error) {
-	stream, err := c.cc.NewStream(ctx, &TodoService_ServiceDesc.Streams[1], "/todo.TodoService/GetUserTodos", opts...)
+	stream, err := c.cc.NewStream(ctx, &TodoService_ServiceDesc.Streams[2], "/todo.TodoService/GetUserTodos", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +157,15 @@ func (x *todoServiceGetUserTodosClient) Recv() (*GetUserTodosResponse, error) {
 	return m, nil
 }
 
+func (c *todoServiceClient) GetTodo(ctx context.Context, in *GetTodoRequest, opts ...grpc.CallOption) (*GetTodoResponse, error) {
+	out := new(GetTodoResponse)
+	err := c.cc.Invoke(ctx, "/todo.TodoService/GetTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *todoServiceClient) DeleteUserTodos(ctx context.Context, in *DeleteUserTodosRequest, opts ...grpc.CallOption) (*DeleteUserTodosResponse, error) {
 	out := new(DeleteUserTodosResponse)
 	err := c.cc.Invoke(ctx, "/todo.TodoService/DeleteUserTodos", in, out, opts...)
@@ -132,16 +184,145 @@ func (c *todoServiceClient) GetUserTodoItemsWithHash(ctx context.Context, in *Ge
 	return out, nil
 }
 
+func (c *todoServiceClient) StreamUserTodoItemsWithHash(ctx context.Context, in *StreamUserTodoItemsWithHashRequest, opts ...grpc.CallOption) (TodoService_StreamUserTodoItemsWithHashClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TodoService_ServiceDesc.Streams[3], "/todo.TodoService/StreamUserTodoItemsWithHash", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoServiceStreamUserTodoItemsWithHashClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TodoService_StreamUserTodoItemsWithHashClient interface {
+	Recv() (*TodoItemWithHash, error)
+	grpc.ClientStream
+}
+
+type todoServiceStreamUserTodoItemsWithHashClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoServiceStreamUserTodoItemsWithHashClient) Recv() (*TodoItemWithHash, error) {
+	m := new(TodoItemWithHash)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *todoServiceClient) UpdateTodo(ctx context.Context, in *UpdateTodoRequest, opts ...grpc.CallOption) (*UpdateTodoResponse, error) {
+	out := new(UpdateTodoResponse)
+	err := c.cc.Invoke(ctx, "/todo.TodoService/UpdateTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) DeleteTodo(ctx context.Context, in *DeleteTodoRequest, opts ...grpc.CallOption) (*DeleteTodoResponse, error) {
+	out := new(DeleteTodoResponse)
+	err := c.cc.Invoke(ctx, "/todo.TodoService/DeleteTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) CompleteTodo(ctx context.Context, in *CompleteTodoRequest, opts ...grpc.CallOption) (*CompleteTodoResponse, error) {
+	out := new(CompleteTodoResponse)
+	err := c.cc.Invoke(ctx, "/todo.TodoService/CompleteTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) ReopenTodo(ctx context.Context, in *ReopenTodoRequest, opts ...grpc.CallOption) (*ReopenTodoResponse, error) {
+	out := new(ReopenTodoResponse)
+	err := c.cc.Invoke(ctx, "/todo.TodoService/ReopenTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) SetTodoDueDate(ctx context.Context, in *SetTodoDueDateRequest, opts ...grpc.CallOption) (*SetTodoDueDateResponse, error) {
+	out := new(SetTodoDueDateResponse)
+	err := c.cc.Invoke(ctx, "/todo.TodoService/SetTodoDueDate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) SetTodoPriority(ctx context.Context, in *SetTodoPriorityRequest, opts ...grpc.CallOption) (*SetTodoPriorityResponse, error) {
+	out := new(SetTodoPriorityResponse)
+	err := c.cc.Invoke(ctx, "/todo.TodoService/SetTodoPriority", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) WatchTodos(ctx context.Context, in *WatchTodosRequest, opts ...grpc.CallOption) (TodoService_WatchTodosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TodoService_ServiceDesc.Streams[4], "/todo.TodoService/WatchTodos", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoServiceWatchTodosClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TodoService_WatchTodosClient interface {
+	Recv() (*TodoEvent, error)
+	grpc.ClientStream
+}
+
+type todoServiceWatchTodosClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoServiceWatchTodosClient) Recv() (*TodoEvent, error) {
+	m := new(TodoEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TodoServiceServer is the server API for TodoService service.
 // All implementations must embed UnimplementedTodoServiceServer
 // for forward compatibility
 type TodoServiceServer interface {
 	AddTodo(context.Context, *AddTodoRequest) (*AddTodoResponse, error)
-	GetAllTodos(context.Context, *NoParams) (*GetAllTodosResponse, error)
+	GetAllTodos(context.Context, *GetAllTodosRequest) (*GetAllTodosResponse, error)
+	// GetAllTodosStreaming keeps its original messages for existing clients, it can't be resumed
 	GetAllTodosStreaming(*NoParams, TodoService_GetAllTodosStreamingServer) error
+	GetAllTodosStreamingWithCursor(*GetAllTodosStreamingRequest, TodoService_GetAllTodosStreamingWithCursorServer) error
 	GetUserTodos(TodoService_GetUserTodosServer) error
+	GetTodo(context.Context, *GetTodoRequest) (*GetTodoResponse, error)
 	DeleteUserTodos(context.Context, *DeleteUserTodosRequest) (*DeleteUserTodosResponse, error)
 	GetUserTodoItemsWithHash(context.Context, *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error)
+	StreamUserTodoItemsWithHash(*StreamUserTodoItemsWithHashRequest, TodoService_StreamUserTodoItemsWithHashServer) error
+	UpdateTodo(context.Context, *UpdateTodoRequest) (*UpdateTodoResponse, error)
+	DeleteTodo(context.Context, *DeleteTodoRequest) (*DeleteTodoResponse, error)
+	CompleteTodo(context.Context, *CompleteTodoRequest) (*CompleteTodoResponse, error)
+	ReopenTodo(context.Context, *ReopenTodoRequest) (*ReopenTodoResponse, error)
+	SetTodoDueDate(context.Context, *SetTodoDueDateRequest) (*SetTodoDueDateResponse, error)
+	SetTodoPriority(context.Context, *SetTodoPriorityRequest) (*SetTodoPriorityResponse, error)
+	WatchTodos(*WatchTodosRequest, TodoService_WatchTodosServer) error
 	mustEmbedUnimplementedTodoServiceServer()
 }
 
@@ -152,21 +333,51 @@ type UnimplementedTodoServiceServer struct {
 func (UnimplementedTodoServiceServer) AddTodo(context.Context, *AddTodoRequest) (*AddTodoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddTodo not implemented")
 }
-func (UnimplementedTodoServiceServer) GetAllTodos(context.Context, *NoParams) (*GetAllTodosResponse, error) {
+func (UnimplementedTodoServiceServer) GetAllTodos(context.Context, *GetAllTodosRequest) (*GetAllTodosResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllTodos not implemented")
 }
 func (UnimplementedTodoServiceServer) GetAllTodosStreaming(*NoParams, TodoService_GetAllTodosStreamingServer) error {
 	return status.Errorf(codes.Unimplemented, "method GetAllTodosStreaming not implemented")
 }
+func (UnimplementedTodoServiceServer) GetAllTodosStreamingWithCursor(*GetAllTodosStreamingRequest, TodoService_GetAllTodosStreamingWithCursorServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetAllTodosStreamingWithCursor not implemented")
+}
 func (UnimplementedTodoServiceServer) GetUserTodos(TodoService_GetUserTodosServer) error {
 	return status.Errorf(codes.Unimplemented, "method GetUserTodos not implemented")
 }
+func (UnimplementedTodoServiceServer) GetTodo(context.Context, *GetTodoRequest) (*GetTodoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTodo not implemented")
+}
 func (UnimplementedTodoServiceServer) DeleteUserTodos(context.Context, *DeleteUserTodosRequest) (*DeleteUserTodosResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserTodos not implemented")
 }
 func (UnimplementedTodoServiceServer) GetUserTodoItemsWithHash(context.Context, *GetUserTodoItemsWithHashRequest) (*GetUserTodoItemsWithHashResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserTodoItemsWithHash not implemented")
 }
+func (UnimplementedTodoServiceServer) StreamUserTodoItemsWithHash(*StreamUserTodoItemsWithHashRequest, TodoService_StreamUserTodoItemsWithHashServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUserTodoItemsWithHash not implemented")
+}
+func (UnimplementedTodoServiceServer) UpdateTodo(context.Context, *UpdateTodoRequest) (*UpdateTodoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTodo not implemented")
+}
+func (UnimplementedTodoServiceServer) DeleteTodo(context.Context, *DeleteTodoRequest) (*DeleteTodoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTodo not implemented")
+}
+func (UnimplementedTodoServiceServer) CompleteTodo(context.Context, *CompleteTodoRequest) (*CompleteTodoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteTodo not implemented")
+}
+func (UnimplementedTodoServiceServer) ReopenTodo(context.Context, *ReopenTodoRequest) (*ReopenTodoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReopenTodo not implemented")
+}
+func (UnimplementedTodoServiceServer) SetTodoDueDate(context.Context, *SetTodoDueDateRequest) (*SetTodoDueDateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTodoDueDate not implemented")
+}
+func (UnimplementedTodoServiceServer) SetTodoPriority(context.Context, *SetTodoPriorityRequest) (*SetTodoPriorityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTodoPriority not implemented")
+}
+func (UnimplementedTodoServiceServer) WatchTodos(*WatchTodosRequest, TodoService_WatchTodosServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTodos not implemented")
+}
 func (UnimplementedTodoServiceServer) mustEmbedUnimplementedTodoServiceServer() {}
 
 // UnsafeTodoServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -199,7 +410,7 @@ func _TodoService_AddTodo_Handler(srv interface{}, ctx context.Context, dec func
 }
 
 func _TodoService_GetAllTodos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(NoParams)
+	in := new(GetAllTodosRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -211,7 +422,7 @@ func _TodoService_GetAllTodos_Handler(srv interface{}, ctx context.Context, dec
 		FullMethod: "/todo.TodoService/GetAllTodos",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TodoServiceServer).GetAllTodos(ctx, req.(*NoParams))
+		return srv.(TodoServiceServer).GetAllTodos(ctx, req.(*GetAllTodosRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -237,6 +448,27 @@ func (x *todoServiceGetAllTodosStreamingServer) Send(m *TodoItem) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _TodoService_GetAllTodosStreamingWithCursor_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAllTodosStreamingRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoServiceServer).GetAllTodosStreamingWithCursor(m, &todoServiceGetAllTodosStreamingWithCursorServer{stream})
+}
+
+type TodoService_GetAllTodosStreamingWithCursorServer interface {
+	Send(*GetAllTodosStreamingResponse) error
+	grpc.ServerStream
+}
+
+type todoServiceGetAllTodosStreamingWithCursorServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoServiceGetAllTodosStreamingWithCursorServer) Send(m *GetAllTodosStreamingResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _TodoService_GetUserTodos_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(TodoServiceServer).GetUserTodos(&todoServiceGetUserTodosServer{stream})
 }
@@ -263,6 +495,24 @@ func (x *todoServiceGetUserTodosServer) Recv() (*GetUserTodosRequest, error) {
 	return m, nil
 }
 
+func _TodoService_GetTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTodoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).GetTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.TodoService/GetTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).GetTodo(ctx, req.(*GetTodoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TodoService_DeleteUserTodos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteUserTodosRequest)
 	if err := dec(in); err != nil {
@@ -299,6 +549,156 @@ func _TodoService_GetUserTodoItemsWithHash_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoService_StreamUserTodoItemsWithHash_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUserTodoItemsWithHashRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoServiceServer).StreamUserTodoItemsWithHash(m, &todoServiceStreamUserTodoItemsWithHashServer{stream})
+}
+
+type TodoService_StreamUserTodoItemsWithHashServer interface {
+	Send(*TodoItemWithHash) error
+	grpc.ServerStream
+}
+
+type todoServiceStreamUserTodoItemsWithHashServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoServiceStreamUserTodoItemsWithHashServer) Send(m *TodoItemWithHash) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TodoService_UpdateTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTodoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).UpdateTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.TodoService/UpdateTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).UpdateTodo(ctx, req.(*UpdateTodoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_DeleteTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTodoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).DeleteTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.TodoService/DeleteTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).DeleteTodo(ctx, req.(*DeleteTodoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_CompleteTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteTodoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).CompleteTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.TodoService/CompleteTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).CompleteTodo(ctx, req.(*CompleteTodoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_ReopenTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReopenTodoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).ReopenTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.TodoService/ReopenTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).ReopenTodo(ctx, req.(*ReopenTodoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_SetTodoDueDate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTodoDueDateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).SetTodoDueDate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.TodoService/SetTodoDueDate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).SetTodoDueDate(ctx, req.(*SetTodoDueDateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_SetTodoPriority_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTodoPriorityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).SetTodoPriority(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.TodoService/SetTodoPriority",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).SetTodoPriority(ctx, req.(*SetTodoPriorityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_WatchTodos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTodosRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoServiceServer).WatchTodos(m, &todoServiceWatchTodosServer{stream})
+}
+
+type TodoService_WatchTodosServer interface {
+	Send(*TodoEvent) error
+	grpc.ServerStream
+}
+
+type todoServiceWatchTodosServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoServiceWatchTodosServer) Send(m *TodoEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // TodoService_ServiceDesc is the grpc.ServiceDesc for TodoService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -314,6 +714,10 @@ var TodoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllTodos",
 			Handler:    _TodoService_GetAllTodos_Handler,
 		},
+		{
+			MethodName: "GetTodo",
+			Handler:    _TodoService_GetTodo_Handler,
+		},
 		{
 			MethodName: "DeleteUserTodos",
 			Handler:    _TodoService_DeleteUserTodos_Handler,
@@ -322,6 +726,30 @@ var TodoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserTodoItemsWithHash",
 			Handler:    _TodoService_GetUserTodoItemsWithHash_Handler,
 		},
+		{
+			MethodName: "UpdateTodo",
+			Handler:    _TodoService_UpdateTodo_Handler,
+		},
+		{
+			MethodName: "DeleteTodo",
+			Handler:    _TodoService_DeleteTodo_Handler,
+		},
+		{
+			MethodName: "CompleteTodo",
+			Handler:    _TodoService_CompleteTodo_Handler,
+		},
+		{
+			MethodName: "ReopenTodo",
+			Handler:    _TodoService_ReopenTodo_Handler,
+		},
+		{
+			MethodName: "SetTodoDueDate",
+			Handler:    _TodoService_SetTodoDueDate_Handler,
+		},
+		{
+			MethodName: "SetTodoPriority",
+			Handler:    _TodoService_SetTodoPriority_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -329,12 +757,27 @@ var TodoService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _TodoService_GetAllTodosStreaming_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "GetAllTodosStreamingWithCursor",
+			Handler:       _TodoService_GetAllTodosStreamingWithCursor_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "GetUserTodos",
 			Handler:       _TodoService_GetUserTodos_Handler,
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "StreamUserTodoItemsWithHash",
+			Handler:       _TodoService_StreamUserTodoItemsWithHash_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchTodos",
+			Handler:       _TodoService_WatchTodos_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "todo.proto",
 }
@@ -12,8 +12,11 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type testingDB struct {
@@ -21,16 +24,45 @@ type testingDB struct {
 	todosResp []*models.TodoItem
 	err       error
 	data      []*models.TodoItem
+	//versions counts the writes to the todos of each user in data
+	versions map[int32]int64
 }
 
-func (this *testingDB) InsertTodoItem(item *models.TodoItem) (int32, error) {
+func (this *testingDB) touch(userID int32) {
+	if this.versions == nil {
+		this.versions = make(map[int32]int64)
+	}
+	this.versions[userID]++
+}
+
+func (this *testingDB) InsertTodoItem(ctx context.Context, item *models.TodoItem) (int32, error) {
 	return this.intResp, this.err
 }
-func (this *testingDB) GetAllTodos() ([]*models.TodoItem, error) {
+func (this *testingDB) GetAllTodos(ctx context.Context) ([]*models.TodoItem, error) {
 	return this.todosResp, this.err
 }
 
-func (this *testingDB) GetUserTodos(userID int32) ([]*models.TodoItem, error) {
+func (this *testingDB) GetAllTodosPage(ctx context.Context, afterID int32, limit int) ([]*models.TodoItem, error) {
+	return limitTodos(this.todosResp, afterID, limit), this.err
+}
+
+func (this *testingDB) GetUserTodosPage(ctx context.Context, userID int32, afterID int32, limit int) ([]*models.TodoItem, error) {
+	todos, err := this.GetUserTodos(ctx, userID)
+	return limitTodos(todos, afterID, limit), err
+}
+
+//limitTodos returns up to limit todos after afterID, todos must be sorted by id
+func limitTodos(todos []*models.TodoItem, afterID int32, limit int) []*models.TodoItem {
+	var res []*models.TodoItem
+	for _, todo := range todos {
+		if todo.TodoID > afterID && len(res) < limit {
+			res = append(res, todo)
+		}
+	}
+	return res
+}
+
+func (this *testingDB) GetUserTodos(ctx context.Context, userID int32) ([]*models.TodoItem, error) {
 	var todos []*models.TodoItem
 	for _, todo := range this.data {
 		if todo.UserID == userID {
@@ -40,12 +72,13 @@ func (this *testingDB) GetUserTodos(userID int32) ([]*models.TodoItem, error) {
 	return todos, this.err
 }
 
-func (this *testingDB) DeleteUserTodos(userID int32) error {
+func (this *testingDB) DeleteUserTodos(ctx context.Context, userID int32) error {
 	if this.err != nil {
 		return this.err
 	}
 	for i := 0; i < len(this.data); i++ {
 		if this.data[i].UserID == userID {
+			this.touch(userID)
 			//delete index i
 			copy(this.data[i:], this.data[i+1:])     // Shift a[i+1:] left one index.
 			this.data = this.data[:len(this.data)-1] // Truncate slice
@@ -54,12 +87,114 @@ func (this *testingDB) DeleteUserTodos(userID int32) error {
 	}
 	return this.err
 }
-func (this *testingDB) Truncate() error {
+
+func (this *testingDB) UpdateTodoItem(ctx context.Context, item *models.TodoItem) error {
+	if this.err != nil {
+		return this.err
+	}
+	for _, todo := range this.data {
+		if todo.TodoID == item.TodoID {
+			todo.Todo = item.Todo
+			this.touch(todo.UserID)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (this *testingDB) GetTodoItem(ctx context.Context, todoID int32) (*models.TodoItem, error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	for _, todo := range this.data {
+		if todo.TodoID == todoID {
+			return todo, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (this *testingDB) SetTodoCompleted(ctx context.Context, todoID int32, completed bool) error {
+	if this.err != nil {
+		return this.err
+	}
+	for _, todo := range this.data {
+		if todo.TodoID == todoID {
+			todo.Completed = completed
+			todo.CompletedAt = nil
+			if completed {
+				completedAt := testingTime
+				todo.CompletedAt = &completedAt
+			}
+			this.touch(todo.UserID)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (this *testingDB) SetTodoDueDate(ctx context.Context, todoID int32, dueDate *time.Time) error {
+	if this.err != nil {
+		return this.err
+	}
+	for _, todo := range this.data {
+		if todo.TodoID == todoID {
+			todo.DueDate = dueDate
+			this.touch(todo.UserID)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (this *testingDB) SetTodoPriority(ctx context.Context, todoID int32, priority models.Priority) error {
+	if this.err != nil {
+		return this.err
+	}
+	for _, todo := range this.data {
+		if todo.TodoID == todoID {
+			todo.Priority = priority
+			this.touch(todo.UserID)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (this *testingDB) DeleteTodoItem(ctx context.Context, todoID int32) error {
+	if this.err != nil {
+		return this.err
+	}
+	for i := 0; i < len(this.data); i++ {
+		if this.data[i].TodoID == todoID {
+			this.touch(this.data[i].UserID)
+			this.data = append(this.data[:i], this.data[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (this *testingDB) Truncate(ctx context.Context) error {
+	return this.err
+}
+
+func (this *testingDB) GetUserTodosVersion(ctx context.Context, userID int32) (int64, error) {
+	return this.versions[userID], this.err
+}
+
+func (this *testingDB) Ping(ctx context.Context) error {
 	return this.err
 }
 
+func (this *testingDB) Close() error {
+	return nil
+}
+
 const testingWaitingTime = 10 * time.Millisecond
 
+var testingTime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
 func TestAddTodo(t *testing.T) {
 	testData := []struct {
 		desc    string
@@ -121,7 +256,7 @@ func TestAddTodo(t *testing.T) {
 func TestGetAllTodos(t *testing.T) {
 	testData := []struct {
 		desc    string
-		input   *NoParams
+		input   *GetAllTodosRequest
 		dsResp  []*models.TodoItem
 		dsErr   error
 		wantRes *GetAllTodosResponse
@@ -129,7 +264,7 @@ func TestGetAllTodos(t *testing.T) {
 	}{
 		{
 			desc:   "Empty response",
-			input:  &NoParams{},
+			input:  &GetAllTodosRequest{},
 			dsResp: []*models.TodoItem{},
 			dsErr:  nil,
 			wantRes: &GetAllTodosResponse{
@@ -139,7 +274,7 @@ func TestGetAllTodos(t *testing.T) {
 		},
 		{
 			desc:  "one todo item",
-			input: &NoParams{},
+			input: &GetAllTodosRequest{},
 			dsResp: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
 			},
@@ -153,7 +288,7 @@ func TestGetAllTodos(t *testing.T) {
 		},
 		{
 			desc:  "multiple todo items",
-			input: &NoParams{},
+			input: &GetAllTodosRequest{},
 			dsResp: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
 				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
@@ -171,9 +306,59 @@ func TestGetAllTodos(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			desc:  "first page",
+			input: &GetAllTodosRequest{PageSize: 2},
+			dsResp: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+			},
+			dsErr: nil,
+			wantRes: &GetAllTodosResponse{
+				Items: []*TodoItem{
+					&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+					&TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+				},
+				NextPageToken: encodePageToken(2),
+			},
+			wantErr: false,
+		},
+		{
+			desc:  "last page",
+			input: &GetAllTodosRequest{PageSize: 2, PageToken: encodePageToken(2)},
+			dsResp: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+			},
+			dsErr: nil,
+			wantRes: &GetAllTodosResponse{
+				Items: []*TodoItem{
+					&TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			desc:    "invalid page token",
+			input:   &GetAllTodosRequest{PageToken: "invalid"},
+			dsResp:  []*models.TodoItem{},
+			dsErr:   nil,
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			desc:    "negative page size",
+			input:   &GetAllTodosRequest{PageSize: -1},
+			dsResp:  []*models.TodoItem{},
+			dsErr:   nil,
+			wantRes: nil,
+			wantErr: true,
+		},
 		{
 			desc:    "Error response",
-			input:   &NoParams{},
+			input:   &GetAllTodosRequest{},
 			dsResp:  nil,
 			dsErr:   errors.New("Invalid"),
 			wantRes: nil,
@@ -188,127 +373,599 @@ func TestGetAllTodos(t *testing.T) {
 		fakeDS := testingDB{}
 		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
 
-		fakeDS.todosResp = tc.dsResp
+		fakeDS.todosResp = tc.dsResp
+		fakeDS.err = tc.dsErr
+
+		got, err := server.GetAllTodos(ctx, tc.input)
+
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("[%q]: GetAllTodos() got success, want an error", tc.desc)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("[%q]: GetAllTodos() got error %v, want success", tc.desc, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: GetAllTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+	}
+}
+
+func TestDeleteUserTodos(t *testing.T) {
+	testData := []struct {
+		desc       string
+		input      *DeleteUserTodosRequest
+		dsData     []*models.TodoItem
+		dsErr      error
+		wantRes    *DeleteUserTodosResponse
+		wantDsData []*models.TodoItem
+		wantErr    bool
+	}{
+		{
+			desc:  "Delete no items",
+			input: &DeleteUserTodosRequest{UserID: 4},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			dsErr:   nil,
+			wantRes: &DeleteUserTodosResponse{},
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			wantErr: false,
+		},
+		{
+			desc:  "Delete one items",
+			input: &DeleteUserTodosRequest{UserID: 3},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			dsErr:   nil,
+			wantRes: &DeleteUserTodosResponse{},
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			wantErr: false,
+		},
+		{
+			desc:  "Delete multiple items",
+			input: &DeleteUserTodosRequest{UserID: 1},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			dsErr:   nil,
+			wantRes: &DeleteUserTodosResponse{},
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+			},
+			wantErr: false,
+		},
+		{
+			desc:  "no delete error",
+			input: &DeleteUserTodosRequest{UserID: 4},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			dsErr:   errors.New("Invalid"),
+			wantRes: &DeleteUserTodosResponse{},
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			wantErr: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testData {
+
+		fakeDS := testingDB{}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+		fakeDS.data = tc.dsData
+		fakeDS.err = tc.dsErr
+
+		got, err := server.DeleteUserTodos(ctx, tc.input)
+
+		if diff := cmp.Diff(tc.wantDsData, fakeDS.data); diff != "" {
+			t.Errorf("[%q]: DeleteUserTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("[%q]: DeleteUserTodos() got success, want an error", tc.desc)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("[%q]: DeleteUserTodos() got error %v, want success", tc.desc, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: DeleteUserTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+	}
+}
+
+func TestUpdateTodo(t *testing.T) {
+	testData := []struct {
+		desc       string
+		input      *UpdateTodoRequest
+		dsData     []*models.TodoItem
+		dsErr      error
+		wantRes    *UpdateTodoResponse
+		wantDsData []*models.TodoItem
+		wantCode   codes.Code
+	}{
+		{
+			desc:  "Update existing item",
+			input: &UpdateTodoRequest{Item: &TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2 fixed"}},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+			},
+			dsErr:   nil,
+			wantRes: &UpdateTodoResponse{Item: &TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2 fixed"}},
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2 fixed"},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			desc:  "Update returns the stored item",
+			input: &UpdateTodoRequest{Item: &TodoItem{TodoID: 2, UserID: 9, Todo: "Task 2 fixed"}},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2", Completed: true, CreatedAt: testingTime},
+			},
+			dsErr:   nil,
+			wantRes: &UpdateTodoResponse{Item: &TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2 fixed", Completed: true, CreatedAt: timestamppb.New(testingTime)}},
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2 fixed", Completed: true, CreatedAt: testingTime},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			desc:  "Update missing item",
+			input: &UpdateTodoRequest{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 3"}},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr: nil,
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			desc:  "Update error",
+			input: &UpdateTodoRequest{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1 fixed"}},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr: errors.New("Invalid"),
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testData {
+
+		fakeDS := testingDB{}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+		fakeDS.data = tc.dsData
+		fakeDS.err = tc.dsErr
+
+		got, err := server.UpdateTodo(ctx, tc.input)
+
+		if diff := cmp.Diff(tc.wantDsData, fakeDS.data); diff != "" {
+			t.Errorf("[%q]: UpdateTodo() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: UpdateTodo() got code %v, want %v", tc.desc, code, tc.wantCode)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: UpdateTodo() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+	}
+}
+
+func TestDeleteTodo(t *testing.T) {
+	testData := []struct {
+		desc       string
+		input      *DeleteTodoRequest
+		dsData     []*models.TodoItem
+		dsErr      error
+		wantRes    *DeleteTodoResponse
+		wantDsData []*models.TodoItem
+		wantCode   codes.Code
+	}{
+		{
+			desc:  "Delete existing item",
+			input: &DeleteTodoRequest{TodoID: 2},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+			},
+			dsErr:   nil,
+			wantRes: &DeleteTodoResponse{},
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			desc:  "Delete missing item",
+			input: &DeleteTodoRequest{TodoID: 4},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr: nil,
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			desc:  "Delete error",
+			input: &DeleteTodoRequest{TodoID: 1},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr: errors.New("Invalid"),
+			wantDsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testData {
+
+		fakeDS := testingDB{}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+		fakeDS.data = tc.dsData
+		fakeDS.err = tc.dsErr
+
+		got, err := server.DeleteTodo(ctx, tc.input)
+
+		if diff := cmp.Diff(tc.wantDsData, fakeDS.data); diff != "" {
+			t.Errorf("[%q]: DeleteTodo() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: DeleteTodo() got code %v, want %v", tc.desc, code, tc.wantCode)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: DeleteTodo() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+	}
+}
+
+func TestCompleteTodo(t *testing.T) {
+	testData := []struct {
+		desc     string
+		input    *CompleteTodoRequest
+		dsData   []*models.TodoItem
+		dsErr    error
+		wantRes  *CompleteTodoResponse
+		wantCode codes.Code
+	}{
+		{
+			desc:  "Complete open item",
+			input: &CompleteTodoRequest{TodoID: 1},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Priority: models.PriorityHigh},
+			},
+			dsErr: nil,
+			wantRes: &CompleteTodoResponse{
+				Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Priority: Priority_PRIORITY_HIGH, Completed: true, CompletedAt: timestamppb.New(testingTime)},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			desc:  "Complete missing item",
+			input: &CompleteTodoRequest{TodoID: 2},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr:    nil,
+			wantCode: codes.NotFound,
+		},
+		{
+			desc:  "Complete error",
+			input: &CompleteTodoRequest{TodoID: 1},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr:    errors.New("Invalid"),
+			wantCode: codes.Internal,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testData {
+
+		fakeDS := testingDB{}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+		fakeDS.data = tc.dsData
+		fakeDS.err = tc.dsErr
+
+		got, err := server.CompleteTodo(ctx, tc.input)
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: CompleteTodo() got code %v, want %v", tc.desc, code, tc.wantCode)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: CompleteTodo() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+	}
+}
+
+func TestReopenTodo(t *testing.T) {
+	completedAt := testingTime
+
+	testData := []struct {
+		desc     string
+		input    *ReopenTodoRequest
+		dsData   []*models.TodoItem
+		dsErr    error
+		wantRes  *ReopenTodoResponse
+		wantCode codes.Code
+	}{
+		{
+			desc:  "Reopen completed item",
+			input: &ReopenTodoRequest{TodoID: 1},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Completed: true, CompletedAt: &completedAt},
+			},
+			dsErr: nil,
+			wantRes: &ReopenTodoResponse{
+				Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			desc:  "Reopen missing item",
+			input: &ReopenTodoRequest{TodoID: 2},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr:    nil,
+			wantCode: codes.NotFound,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testData {
+
+		fakeDS := testingDB{}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+		fakeDS.data = tc.dsData
+		fakeDS.err = tc.dsErr
+
+		got, err := server.ReopenTodo(ctx, tc.input)
+
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: ReopenTodo() got code %v, want %v", tc.desc, code, tc.wantCode)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: ReopenTodo() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			continue
+		}
+	}
+}
+
+func dialer(fakeServer *Server) func(context.Context, string) (net.Conn, error) {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer()
+
+	RegisterTodoServiceServer(server, fakeServer)
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	return func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
+}
+
+//TestGetAllTodosStreaming checks the original stream still sends bare todo items
+func TestSetTodoDueDate(t *testing.T) {
+	dueDate := testingTime.Add(24 * time.Hour)
+
+	testData := []struct {
+		desc     string
+		input    *SetTodoDueDateRequest
+		dsData   []*models.TodoItem
+		dsErr    error
+		wantRes  *SetTodoDueDateResponse
+		wantCode codes.Code
+	}{
+		{
+			desc:  "Set due date",
+			input: &SetTodoDueDateRequest{TodoID: 1, DueDate: timestamppb.New(dueDate)},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Priority: models.PriorityHigh},
+			},
+			dsErr: nil,
+			wantRes: &SetTodoDueDateResponse{
+				Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Priority: Priority_PRIORITY_HIGH, DueDate: timestamppb.New(dueDate)},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			desc:  "Clear due date",
+			input: &SetTodoDueDateRequest{TodoID: 1},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", DueDate: &dueDate},
+			},
+			dsErr:    nil,
+			wantRes:  &SetTodoDueDateResponse{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}},
+			wantCode: codes.OK,
+		},
+		{
+			desc:  "Set due date of missing item",
+			input: &SetTodoDueDateRequest{TodoID: 2, DueDate: timestamppb.New(dueDate)},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr:    nil,
+			wantCode: codes.NotFound,
+		},
+		{
+			desc:  "Set due date error",
+			input: &SetTodoDueDateRequest{TodoID: 1, DueDate: timestamppb.New(dueDate)},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+			},
+			dsErr:    errors.New("Invalid"),
+			wantCode: codes.Internal,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testData {
+
+		fakeDS := testingDB{}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+
+		fakeDS.data = tc.dsData
 		fakeDS.err = tc.dsErr
 
-		got, err := server.GetAllTodos(ctx, tc.input)
-
-		if tc.wantErr {
-			if err == nil {
-				t.Errorf("[%q]: GetAllTodos() got success, want an error", tc.desc)
-			}
-			continue
-		}
+		got, err := server.SetTodoDueDate(ctx, tc.input)
 
-		if err != nil {
-			t.Errorf("[%q]: GetAllTodos() got error %v, want success", tc.desc, err)
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: SetTodoDueDate() got code %v, want %v", tc.desc, code, tc.wantCode)
 			continue
 		}
 
 		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
-			t.Errorf("[%q]: GetAllTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			t.Errorf("[%q]: SetTodoDueDate() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
 			continue
 		}
 	}
 }
 
-func TestDeleteUserTodos(t *testing.T) {
+func TestSetTodoPriority(t *testing.T) {
+	dueDate := testingTime.Add(24 * time.Hour)
+
 	testData := []struct {
-		desc       string
-		input      *DeleteUserTodosRequest
-		dsData     []*models.TodoItem
-		dsErr      error
-		wantRes    *DeleteUserTodosResponse
-		wantDsData []*models.TodoItem
-		wantErr    bool
+		desc     string
+		input    *SetTodoPriorityRequest
+		dsData   []*models.TodoItem
+		dsErr    error
+		wantRes  *SetTodoPriorityResponse
+		wantCode codes.Code
 	}{
 		{
-			desc:  "Delete no items",
-			input: &DeleteUserTodosRequest{UserID: 4},
-			dsData: []*models.TodoItem{
-				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
-			},
-			dsErr:   nil,
-			wantRes: &DeleteUserTodosResponse{},
-			wantDsData: []*models.TodoItem{
-				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
-			},
-			wantErr: false,
-		},
-		{
-			desc:  "Delete one items",
-			input: &DeleteUserTodosRequest{UserID: 3},
+			desc:  "Set priority",
+			input: &SetTodoPriorityRequest{TodoID: 1, Priority: Priority_PRIORITY_LOW},
 			dsData: []*models.TodoItem{
-				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Priority: models.PriorityHigh, DueDate: &dueDate},
 			},
-			dsErr:   nil,
-			wantRes: &DeleteUserTodosResponse{},
-			wantDsData: []*models.TodoItem{
-				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			dsErr: nil,
+			wantRes: &SetTodoPriorityResponse{
+				Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1", Priority: Priority_PRIORITY_LOW, DueDate: timestamppb.New(dueDate)},
 			},
-			wantErr: false,
+			wantCode: codes.OK,
 		},
 		{
-			desc:  "Delete multiple items",
-			input: &DeleteUserTodosRequest{UserID: 1},
+			desc:  "Set priority of missing item",
+			input: &SetTodoPriorityRequest{TodoID: 2, Priority: Priority_PRIORITY_LOW},
 			dsData: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
-			},
-			dsErr:   nil,
-			wantRes: &DeleteUserTodosResponse{},
-			wantDsData: []*models.TodoItem{
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
 			},
-			wantErr: false,
+			dsErr:    nil,
+			wantCode: codes.NotFound,
 		},
 		{
-			desc:  "no delete error",
-			input: &DeleteUserTodosRequest{UserID: 4},
+			desc:  "Set priority error",
+			input: &SetTodoPriorityRequest{TodoID: 1, Priority: Priority_PRIORITY_LOW},
 			dsData: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
-			},
-			dsErr:   errors.New("Invalid"),
-			wantRes: &DeleteUserTodosResponse{},
-			wantDsData: []*models.TodoItem{
-				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
-				&models.TodoItem{TodoID: 5, UserID: 2, Todo: "Task 2"},
-				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
 			},
-			wantErr: true,
+			dsErr:    errors.New("Invalid"),
+			wantCode: codes.Internal,
 		},
 	}
 
@@ -322,54 +979,62 @@ func TestDeleteUserTodos(t *testing.T) {
 		fakeDS.data = tc.dsData
 		fakeDS.err = tc.dsErr
 
-		got, err := server.DeleteUserTodos(ctx, tc.input)
-
-		if diff := cmp.Diff(tc.wantDsData, fakeDS.data); diff != "" {
-			t.Errorf("[%q]: DeleteUserTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
-			continue
-		}
-
-		if tc.wantErr {
-			if err == nil {
-				t.Errorf("[%q]: DeleteUserTodos() got success, want an error", tc.desc)
-			}
-			continue
-		}
+		got, err := server.SetTodoPriority(ctx, tc.input)
 
-		if err != nil {
-			t.Errorf("[%q]: DeleteUserTodos() got error %v, want success", tc.desc, err)
+		if code := status.Code(err); code != tc.wantCode {
+			t.Errorf("[%q]: SetTodoPriority() got code %v, want %v", tc.desc, code, tc.wantCode)
 			continue
 		}
 
 		if diff := cmp.Diff(tc.wantRes, got, protocmp.Transform()); diff != "" {
-			t.Errorf("[%q]: DeleteUserTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			t.Errorf("[%q]: SetTodoPriority() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
 			continue
 		}
 	}
 }
 
-func dialer(fakeServer *Server) func(context.Context, string) (net.Conn, error) {
-	listener := bufconn.Listen(1024 * 1024)
-
-	server := grpc.NewServer()
-
-	RegisterTodoServiceServer(server, fakeServer)
+func TestGetAllTodosStreaming(t *testing.T) {
+	fakeDS := testingDB{todosResp: []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+	}}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "", grpc.WithInsecure(), grpc.WithContextDialer(dialer(&server)))
+	if err != nil {
+		t.Fatalf("GetAllTodosStreaming() got error %v", err)
+	}
+	defer conn.Close()
 
-	go func() {
-		if err := server.Serve(listener); err != nil {
-			log.Fatal(err)
+	stream, err := NewTodoServiceClient(conn).GetAllTodosStreaming(ctx, &NoParams{})
+	if err != nil {
+		t.Fatalf("GetAllTodosStreaming() got error %v", err)
+	}
+	var todos []*TodoItem
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			break
 		}
-	}()
+		if err != nil {
+			t.Fatalf("GetAllTodosStreaming() got error %v, want success", err)
+		}
+		todos = append(todos, item)
+	}
 
-	return func(context.Context, string) (net.Conn, error) {
-		return listener.Dial()
+	want := []*TodoItem{
+		&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+	}
+	if diff := cmp.Diff(want, todos, protocmp.Transform()); diff != "" {
+		t.Errorf("GetAllTodosStreaming() returned unexpected diff (-want, +got):\n%s", diff)
 	}
 }
 
-func TestGetAllTodosStreaming(t *testing.T) {
+func TestGetAllTodosStreamingWithCursor(t *testing.T) {
 	testData := []struct {
 		desc    string
-		input   *NoParams
+		input   *GetAllTodosStreamingRequest
 		dsResp  []*models.TodoItem
 		dsErr   error
 		wantRes []*TodoItem
@@ -377,7 +1042,7 @@ func TestGetAllTodosStreaming(t *testing.T) {
 	}{
 		{
 			desc:    "Empty response",
-			input:   &NoParams{},
+			input:   &GetAllTodosStreamingRequest{},
 			dsResp:  []*models.TodoItem{},
 			dsErr:   nil,
 			wantRes: nil,
@@ -385,7 +1050,7 @@ func TestGetAllTodosStreaming(t *testing.T) {
 		},
 		{
 			desc:  "one todo item",
-			input: &NoParams{},
+			input: &GetAllTodosStreamingRequest{},
 			dsResp: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
 			},
@@ -397,7 +1062,7 @@ func TestGetAllTodosStreaming(t *testing.T) {
 		},
 		{
 			desc:  "multiple todo items",
-			input: &NoParams{},
+			input: &GetAllTodosStreamingRequest{},
 			dsResp: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
 				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
@@ -413,9 +1078,33 @@ func TestGetAllTodosStreaming(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			desc:  "resume after cursor",
+			input: &GetAllTodosStreamingRequest{ResumeAfter: encodePageToken(2)},
+			dsResp: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+			},
+			dsErr: nil,
+			wantRes: []*TodoItem{
+				&TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+				&TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+			},
+			wantErr: false,
+		},
+		{
+			desc:    "invalid cursor",
+			input:   &GetAllTodosStreamingRequest{ResumeAfter: "invalid"},
+			dsResp:  nil,
+			dsErr:   nil,
+			wantRes: nil,
+			wantErr: true,
+		},
 		{
 			desc:    "Error response",
-			input:   &NoParams{},
+			input:   &GetAllTodosStreamingRequest{},
 			dsResp:  nil,
 			dsErr:   errors.New("Invalid"),
 			wantRes: nil,
@@ -436,7 +1125,7 @@ func TestGetAllTodosStreaming(t *testing.T) {
 		//init server
 		conn, err := grpc.DialContext(ctx, "", grpc.WithInsecure(), grpc.WithContextDialer(dialer(&server)))
 		if err != nil {
-			t.Errorf("[%q]: GetAllTodosStreaming() got error %v", tc.desc, err)
+			t.Errorf("[%q]: GetAllTodosStreamingWithCursor() got error %v", tc.desc, err)
 			return
 		}
 		defer conn.Close()
@@ -444,16 +1133,16 @@ func TestGetAllTodosStreaming(t *testing.T) {
 		//init client
 		client := NewTodoServiceClient(conn)
 
-		stream, err := client.GetAllTodosStreaming(ctx, tc.input)
+		stream, err := client.GetAllTodosStreamingWithCursor(ctx, tc.input)
 		if err != nil {
-			t.Errorf("[%q]: GetAllTodosStreaming() got error %v", tc.desc, err)
+			t.Errorf("[%q]: GetAllTodosStreamingWithCursor() got error %v", tc.desc, err)
 			continue
 		}
 
 		err = nil
 		var todos []*TodoItem
 		for {
-			item, curErr := stream.Recv()
+			response, curErr := stream.Recv()
 			if curErr == io.EOF {
 				break
 			}
@@ -461,43 +1150,52 @@ func TestGetAllTodosStreaming(t *testing.T) {
 				err = curErr
 				break
 			}
-			todos = append(todos, item)
+			if response.Cursor != encodePageToken(response.Item.TodoID) {
+				t.Errorf("[%q]: GetAllTodosStreamingWithCursor() got cursor %q for todo %d", tc.desc, response.Cursor, response.Item.TodoID)
+			}
+			todos = append(todos, response.Item)
 		}
 
 		if tc.wantErr {
 			if err == nil {
-				t.Errorf("[%q]: GetAllTodosStreaming() got success, want an error", tc.desc)
+				t.Errorf("[%q]: GetAllTodosStreamingWithCursor() got success, want an error", tc.desc)
 			}
 			continue
 		}
 
 		if err != nil {
-			t.Errorf("[%q]: GetAllTodosStreaming() got error %v, want success", tc.desc, err)
+			t.Errorf("[%q]: GetAllTodosStreamingWithCursor() got error %v, want success", tc.desc, err)
 			continue
 		}
 
 		if diff := cmp.Diff(tc.wantRes, todos, protocmp.Transform()); diff != "" {
-			t.Errorf("[%q]: GetAllTodosStreaming() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			t.Errorf("[%q]: GetAllTodosStreamingWithCursor() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
 			continue
 		}
 	}
 
 }
 
-type testing_TodoService_GetAllTodosStreamingServer struct {
+type testing_TodoService_GetAllTodosStreamingWithCursorServer struct {
 	grpc.ServerStream
 	Results []*TodoItem
+	Cursors []string
 }
 
-func (this *testing_TodoService_GetAllTodosStreamingServer) Send(item *TodoItem) error {
-	this.Results = append(this.Results, item)
+func (this *testing_TodoService_GetAllTodosStreamingWithCursorServer) Send(response *GetAllTodosStreamingResponse) error {
+	this.Results = append(this.Results, response.Item)
+	this.Cursors = append(this.Cursors, response.Cursor)
 	return nil
 }
 
-func TestGetAllTodosStreaming2(t *testing.T) {
+func (this *testing_TodoService_GetAllTodosStreamingWithCursorServer) Context() context.Context {
+	return context.Background()
+}
+
+func TestGetAllTodosStreamingWithCursor2(t *testing.T) {
 	testData := []struct {
 		desc    string
-		input   *NoParams
+		input   *GetAllTodosStreamingRequest
 		dsResp  []*models.TodoItem
 		dsErr   error
 		wantRes []*TodoItem
@@ -505,7 +1203,7 @@ func TestGetAllTodosStreaming2(t *testing.T) {
 	}{
 		{
 			desc:    "Empty response",
-			input:   &NoParams{},
+			input:   &GetAllTodosStreamingRequest{},
 			dsResp:  []*models.TodoItem{},
 			dsErr:   nil,
 			wantRes: nil,
@@ -513,7 +1211,7 @@ func TestGetAllTodosStreaming2(t *testing.T) {
 		},
 		{
 			desc:  "one todo item",
-			input: &NoParams{},
+			input: &GetAllTodosStreamingRequest{},
 			dsResp: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
 			},
@@ -525,7 +1223,7 @@ func TestGetAllTodosStreaming2(t *testing.T) {
 		},
 		{
 			desc:  "multiple todo items",
-			input: &NoParams{},
+			input: &GetAllTodosStreamingRequest{},
 			dsResp: []*models.TodoItem{
 				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
 				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
@@ -541,9 +1239,33 @@ func TestGetAllTodosStreaming2(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			desc:  "resume after cursor",
+			input: &GetAllTodosStreamingRequest{ResumeAfter: encodePageToken(2)},
+			dsResp: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+			},
+			dsErr: nil,
+			wantRes: []*TodoItem{
+				&TodoItem{TodoID: 3, UserID: 2, Todo: "Task 1"},
+				&TodoItem{TodoID: 4, UserID: 3, Todo: "Task 1"},
+			},
+			wantErr: false,
+		},
+		{
+			desc:    "invalid cursor",
+			input:   &GetAllTodosStreamingRequest{ResumeAfter: "invalid"},
+			dsResp:  nil,
+			dsErr:   nil,
+			wantRes: nil,
+			wantErr: true,
+		},
 		{
 			desc:    "Error response",
-			input:   &NoParams{},
+			input:   &GetAllTodosStreamingRequest{},
 			dsResp:  nil,
 			dsErr:   errors.New("Invalid"),
 			wantRes: nil,
@@ -559,25 +1281,33 @@ func TestGetAllTodosStreaming2(t *testing.T) {
 		fakeDS.todosResp = tc.dsResp
 		fakeDS.err = tc.dsErr
 
-		stream := &testing_TodoService_GetAllTodosStreamingServer{}
-		err := server.GetAllTodosStreaming(tc.input, stream)
+		stream := &testing_TodoService_GetAllTodosStreamingWithCursorServer{}
+		err := server.GetAllTodosStreamingWithCursor(tc.input, stream)
 
 		if tc.wantErr {
 			if err == nil {
-				t.Errorf("[%q]: GetAllTodosStreaming2() got success, want an error", tc.desc)
+				t.Errorf("[%q]: GetAllTodosStreamingWithCursor2() got success, want an error", tc.desc)
 			}
 			continue
 		}
 
 		if err != nil {
-			t.Errorf("[%q]: GetAllTodosStreaming2() got error %v, want success", tc.desc, err)
+			t.Errorf("[%q]: GetAllTodosStreamingWithCursor2() got error %v, want success", tc.desc, err)
 			continue
 		}
 
 		if diff := cmp.Diff(tc.wantRes, stream.Results, protocmp.Transform()); diff != "" {
-			t.Errorf("[%q]: GetAllTodosStreaming2() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
+			t.Errorf("[%q]: GetAllTodosStreamingWithCursor2() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
 			continue
 		}
+
+		for i := 1; i < len(stream.Cursors); i++ {
+			previous, _ := decodePageToken("cursor", stream.Cursors[i-1])
+			current, _ := decodePageToken("cursor", stream.Cursors[i])
+			if current <= previous {
+				t.Errorf("[%q]: GetAllTodosStreamingWithCursor2() got cursor %d after %d, want increasing cursors", tc.desc, current, previous)
+			}
+		}
 	}
 
 }
@@ -586,6 +1316,7 @@ type testing_TodoService_GetUserTodosServer struct {
 	grpc.ServerStream
 	Results []*GetUserTodosResponse
 	inputs  []*GetUserTodosRequest
+	ctx     context.Context
 }
 
 func (this *testing_TodoService_GetUserTodosServer) Send(item *GetUserTodosResponse) error {
@@ -593,6 +1324,13 @@ func (this *testing_TodoService_GetUserTodosServer) Send(item *GetUserTodosRespo
 	return nil
 }
 
+func (this *testing_TodoService_GetUserTodosServer) Context() context.Context {
+	if this.ctx == nil {
+		return context.Background()
+	}
+	return this.ctx
+}
+
 func (this *testing_TodoService_GetUserTodosServer) Recv() (*GetUserTodosRequest, error) {
 	if len(this.inputs) == 0 {
 		return nil, io.EOF
@@ -701,6 +1439,46 @@ func TestGetUserTodos(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			desc: "paged user response",
+			input: []*GetUserTodosRequest{
+				&GetUserTodosRequest{UserID: 1, PageSize: 2},
+				&GetUserTodosRequest{UserID: 1, PageSize: 2, PageToken: encodePageToken(3)},
+			},
+			dsData: []*models.TodoItem{
+				&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+				&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 4, UserID: 2, Todo: "Task 2"},
+				&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+			},
+			dsErr: nil,
+			wantRes: []*GetUserTodosResponse{
+				&GetUserTodosResponse{
+					Items: []*TodoItem{
+						&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+						&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+					},
+					NextPageToken: encodePageToken(3),
+				},
+				&GetUserTodosResponse{
+					Items: []*TodoItem{
+						&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			desc: "invalid page token",
+			input: []*GetUserTodosRequest{
+				&GetUserTodosRequest{UserID: 1, PageToken: "invalid"},
+			},
+			dsData:  []*models.TodoItem{},
+			dsErr:   nil,
+			wantRes: nil,
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testData {
@@ -726,7 +1504,8 @@ func TestGetUserTodos(t *testing.T) {
 			continue
 		}
 
-		if diff := cmp.Diff(tc.wantRes, stream.Results, protocmp.Transform()); diff != "" {
+		//the etags are covered by TestGetUserTodosNotModified
+		if diff := cmp.Diff(tc.wantRes, stream.Results, protocmp.Transform(), protocmp.IgnoreFields(&GetUserTodosResponse{}, "etag")); diff != "" {
 			t.Errorf("[%q]: GetUserTodos() returned unexpected diff (-want, +got):\n%s", tc.desc, diff)
 			continue
 		}
@@ -776,9 +1555,9 @@ func TestGetUserTodoItemsWithHash(t *testing.T) {
 			dsErr: nil,
 			wantRes: &GetUserTodoItemsWithHashResponse{
 				Items: []*TodoItemWithHash{
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}, Hash: 2},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}, Hash: 4},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}, Hash: 7},
+					hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+					hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+					hashed(&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}),
 				},
 			},
 			wantErr: false,
@@ -841,6 +1620,33 @@ func TestGetUserTodoItemsWithHash(t *testing.T) {
 	}
 }
 
+//blockingDB is a data store whose queries only return when their context is done
+type blockingDB struct {
+	testingDB
+}
+
+func (this *blockingDB) GetUserTodos(ctx context.Context, userID int32) ([]*models.TodoItem, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGetUserTodoItemsWithHashDeadline(t *testing.T) {
+	server := Server{DS: &blockingDB{}, WaitingTime: testingWaitingTime}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := server.GetUserTodoItemsWithHash(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
+
+	if code := status.Code(err); code != codes.DeadlineExceeded {
+		t.Errorf("GetUserTodoItemsWithHash() got code %v, want %v", code, codes.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetUserTodoItemsWithHash() took %v, want to stop at the deadline", elapsed)
+	}
+}
+
 func TestGetUserTodoItemsWithHashAppend(t *testing.T) {
 	testData := []struct {
 		desc    string
@@ -884,9 +1690,9 @@ func TestGetUserTodoItemsWithHashAppend(t *testing.T) {
 			dsErr: nil,
 			wantRes: &GetUserTodoItemsWithHashResponse{
 				Items: []*TodoItemWithHash{
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}, Hash: 2},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}, Hash: 4},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}, Hash: 7},
+					hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+					hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+					hashed(&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}),
 				},
 			},
 			wantErr: false,
@@ -916,7 +1722,7 @@ func TestGetUserTodoItemsWithHashAppend(t *testing.T) {
 	for _, tc := range testData {
 
 		fakeDS := testingDB{}
-		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "append"}
 
 		fakeDS.data = tc.dsData
 		fakeDS.err = tc.dsErr
@@ -924,7 +1730,7 @@ func TestGetUserTodoItemsWithHashAppend(t *testing.T) {
 		childCtx, cancel := context.WithTimeout(ctx, tc.timeOut)
 		defer cancel()
 
-		resp, err := server.GetUserTodoItemsWithHashAppend(childCtx, tc.input)
+		resp, err := server.GetUserTodoItemsWithHash(childCtx, tc.input)
 
 		if tc.wantErr {
 			if err == nil {
@@ -992,9 +1798,9 @@ func TestGetUserTodoItemsWithHashAppendPreAllocation(t *testing.T) {
 			dsErr: nil,
 			wantRes: &GetUserTodoItemsWithHashResponse{
 				Items: []*TodoItemWithHash{
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}, Hash: 2},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}, Hash: 4},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}, Hash: 7},
+					hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+					hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+					hashed(&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}),
 				},
 			},
 			wantErr: false,
@@ -1024,7 +1830,7 @@ func TestGetUserTodoItemsWithHashAppendPreAllocation(t *testing.T) {
 	for _, tc := range testData {
 
 		fakeDS := testingDB{}
-		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "append_preallocated"}
 
 		fakeDS.data = tc.dsData
 		fakeDS.err = tc.dsErr
@@ -1032,7 +1838,7 @@ func TestGetUserTodoItemsWithHashAppendPreAllocation(t *testing.T) {
 		childCtx, cancel := context.WithTimeout(ctx, tc.timeOut)
 		defer cancel()
 
-		resp, err := server.GetUserTodoItemsWithHashAppendPreAllocation(childCtx, tc.input)
+		resp, err := server.GetUserTodoItemsWithHash(childCtx, tc.input)
 
 		if tc.wantErr {
 			if err == nil {
@@ -1100,9 +1906,9 @@ func TestGetUserTodoItemsWithHashPointer(t *testing.T) {
 			dsErr: nil,
 			wantRes: &GetUserTodoItemsWithHashResponse{
 				Items: []*TodoItemWithHash{
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}, Hash: 2},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}, Hash: 4},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}, Hash: 7},
+					hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+					hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+					hashed(&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}),
 				},
 			},
 			wantErr: false,
@@ -1132,7 +1938,7 @@ func TestGetUserTodoItemsWithHashPointer(t *testing.T) {
 	for _, tc := range testData {
 
 		fakeDS := testingDB{}
-		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "pointer"}
 
 		fakeDS.data = tc.dsData
 		fakeDS.err = tc.dsErr
@@ -1140,7 +1946,7 @@ func TestGetUserTodoItemsWithHashPointer(t *testing.T) {
 		childCtx, cancel := context.WithTimeout(ctx, tc.timeOut)
 		defer cancel()
 
-		resp, err := server.GetUserTodoItemsWithHashPointer(childCtx, tc.input)
+		resp, err := server.GetUserTodoItemsWithHash(childCtx, tc.input)
 
 		if tc.wantErr {
 			if err == nil {
@@ -1208,9 +2014,9 @@ func TestGetUserTodoItemsWithHashAppendChannels(t *testing.T) {
 			dsErr: nil,
 			wantRes: &GetUserTodoItemsWithHashResponse{
 				Items: []*TodoItemWithHash{
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}, Hash: 2},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}, Hash: 4},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}, Hash: 7},
+					hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+					hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+					hashed(&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}),
 				},
 			},
 			wantErr: false,
@@ -1240,7 +2046,7 @@ func TestGetUserTodoItemsWithHashAppendChannels(t *testing.T) {
 	for _, tc := range testData {
 
 		fakeDS := testingDB{}
-		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "append_channels"}
 
 		fakeDS.data = tc.dsData
 		fakeDS.err = tc.dsErr
@@ -1248,7 +2054,7 @@ func TestGetUserTodoItemsWithHashAppendChannels(t *testing.T) {
 		childCtx, cancel := context.WithTimeout(ctx, tc.timeOut)
 		defer cancel()
 
-		resp, err := server.GetUserTodoItemsWithHashAppendChannels(childCtx, tc.input)
+		resp, err := server.GetUserTodoItemsWithHash(childCtx, tc.input)
 
 		if tc.wantErr {
 			if err == nil {
@@ -1316,9 +2122,9 @@ func TestGetUserTodoItemsWithHashIndexingChannels(t *testing.T) {
 			dsErr: nil,
 			wantRes: &GetUserTodoItemsWithHashResponse{
 				Items: []*TodoItemWithHash{
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}, Hash: 2},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}, Hash: 4},
-					&TodoItemWithHash{Item: &TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}, Hash: 7},
+					hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+					hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+					hashed(&TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}),
 				},
 			},
 			wantErr: false,
@@ -1348,7 +2154,7 @@ func TestGetUserTodoItemsWithHashIndexingChannels(t *testing.T) {
 	for _, tc := range testData {
 
 		fakeDS := testingDB{}
-		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+		server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "indexing_channels"}
 
 		fakeDS.data = tc.dsData
 		fakeDS.err = tc.dsErr
@@ -1356,7 +2162,7 @@ func TestGetUserTodoItemsWithHashIndexingChannels(t *testing.T) {
 		childCtx, cancel := context.WithTimeout(ctx, tc.timeOut)
 		defer cancel()
 
-		resp, err := server.GetUserTodoItemsWithHashIndexingChannels(childCtx, tc.input)
+		resp, err := server.GetUserTodoItemsWithHash(childCtx, tc.input)
 
 		if tc.wantErr {
 			if err == nil {
@@ -1402,7 +2208,7 @@ func BenchmarkGetUserTodoItemsWithHash(b *testing.B) {
 
 func BenchmarkGetUserTodoItemsWithHashIndexingChannels(b *testing.B) {
 	fakeDS := testingDB{}
-	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "indexing_channels"}
 
 	fakeDS.err = nil
 	for i := 0; i < N; i++ {
@@ -1413,13 +2219,13 @@ func BenchmarkGetUserTodoItemsWithHashIndexingChannels(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		server.GetUserTodoItemsWithHashIndexingChannels(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
+		server.GetUserTodoItemsWithHash(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
 	}
 }
 
 func BenchmarkGetUserTodoItemsWithHashAppendChannels(b *testing.B) {
 	fakeDS := testingDB{}
-	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "append_channels"}
 
 	fakeDS.err = nil
 	for i := 0; i < N; i++ {
@@ -1430,13 +2236,13 @@ func BenchmarkGetUserTodoItemsWithHashAppendChannels(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		server.GetUserTodoItemsWithHashAppendChannels(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
+		server.GetUserTodoItemsWithHash(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
 	}
 }
 
 func BenchmarkGetUserTodoItemsWithHashAppendPreAllocation(b *testing.B) {
 	fakeDS := testingDB{}
-	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "append_preallocated"}
 
 	fakeDS.err = nil
 	for i := 0; i < N; i++ {
@@ -1447,12 +2253,12 @@ func BenchmarkGetUserTodoItemsWithHashAppendPreAllocation(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		server.GetUserTodoItemsWithHashAppendPreAllocation(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
+		server.GetUserTodoItemsWithHash(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
 	}
 }
 func BenchmarkGetUserTodoItemsWithHashPointer(b *testing.B) {
 	fakeDS := testingDB{}
-	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "pointer"}
 
 	fakeDS.err = nil
 	for i := 0; i < N; i++ {
@@ -1463,13 +2269,13 @@ func BenchmarkGetUserTodoItemsWithHashPointer(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		server.GetUserTodoItemsWithHashPointer(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
+		server.GetUserTodoItemsWithHash(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
 	}
 }
 
 func BenchmarkGetUserTodoItemsWithHashAppend(b *testing.B) {
 	fakeDS := testingDB{}
-	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime, Transform: "append"}
 
 	fakeDS.err = nil
 	for i := 0; i < N; i++ {
@@ -1480,6 +2286,6 @@ func BenchmarkGetUserTodoItemsWithHashAppend(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		server.GetUserTodoItemsWithHashAppend(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
+		server.GetUserTodoItemsWithHash(ctx, &GetUserTodoItemsWithHashRequest{UserID: 1})
 	}
 }
@@ -0,0 +1,308 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"todo-app/models"
+)
+
+//DefaultTransform is used when neither the request nor the server choose a strategy
+const DefaultTransform = "indexing"
+
+//ProcessFunc hashes one todo item
+type ProcessFunc func(ctx context.Context, item *TodoItem) (uint64, error)
+
+//ParallelFunc runs the functions concurrently within the worker limits of the request
+//it stops starting functions when one fails or ctx is done, and returns once the started ones returned
+type ParallelFunc func(ctx context.Context, list []func(context.Context) error) error
+
+//Transformer hashes the todos of a request with process, running the work through parallel
+//the order of the returned items is up to the strategy
+type Transformer interface {
+	Transform(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error)
+}
+
+//TransformerFunc adapts a function to a Transformer
+type TransformerFunc func(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error)
+
+func (this TransformerFunc) Transform(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+	return this(ctx, todos, parallel, process)
+}
+
+//TransformObserver is told when a request runs a strategy, the returned function is called with its result
+type TransformObserver interface {
+	Transform(strategy string, items int) (done func(err error))
+}
+
+var (
+	transformMu  sync.RWMutex
+	transformers = map[string]Transformer{
+		"indexing":            TransformerFunc(transformIndexing),
+		"pointer":             TransformerFunc(transformPointer),
+		"append":              TransformerFunc(transformAppend),
+		"append_preallocated": TransformerFunc(transformAppendPreAllocation),
+		"append_channels":     TransformerFunc(transformAppendChannels),
+		"indexing_channels":   TransformerFunc(transformIndexingChannels),
+	}
+)
+
+//RegisterTransformer adds a strategy requests can choose by name, it panics when the name is taken
+func RegisterTransformer(name string, t Transformer) {
+	transformMu.Lock()
+	defer transformMu.Unlock()
+	if _, ok := transformers[name]; ok {
+		panic(fmt.Sprintf("transformer %q registered twice", name))
+	}
+	transformers[name] = t
+}
+
+//Transformers returns the names of the registered strategies in order
+func Transformers() []string {
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+	names := make([]string, 0, len(transformers))
+	for name := range transformers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupTransformer(name string) (Transformer, bool) {
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+	t, ok := transformers[name]
+	return t, ok
+}
+
+//transformer validates the transform field of a request, empty selects the server default
+func (this *violations) transformer(field string, name string) {
+	if _, ok := lookupTransformer(name); name != "" && !ok {
+		this.add(field, "unknown transform %q, must be one of %s", name, strings.Join(Transformers(), ", "))
+	}
+}
+
+//namedTransformer is the strategy chosen for one request
+type namedTransformer struct {
+	name string
+	Transformer
+}
+
+//transformer returns the strategy of the request, or the server default when it's empty
+func (s *Server) transformer(name string) (namedTransformer, error) {
+	if name == "" {
+		name = s.Transform
+	}
+	if name == "" {
+		name = DefaultTransform
+	}
+	t, ok := lookupTransformer(name)
+	if !ok {
+		return namedTransformer{}, invalidArgument("transform", "unknown transform %q", name)
+	}
+	return namedTransformer{name: name, Transformer: t}, nil
+}
+
+//transform runs the strategy on the todos and reports it to the Transforms observer
+func (s *Server) transform(ctx context.Context, t namedTransformer, todos []*models.TodoItem, maxWorkers int32, process ProcessFunc) ([]*TodoItemWithHash, error) {
+	done := func(error) {}
+	if s.Transforms != nil {
+		done = s.Transforms.Transform(t.name, len(todos))
+	}
+	parallel := func(ctx context.Context, list []func(context.Context) error) error {
+		return s.parallel(ctx, maxWorkers, list)
+	}
+	items, err := t.Transform(ctx, todos, parallel, process)
+	done(err)
+	return items, err
+}
+
+//transformIndexing writes each hashed item at the index of its todo
+func transformIndexing(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+
+	response := make([]*TodoItemWithHash, len(todos))
+
+	f := func(ctx context.Context, idx int, item *TodoItem) error {
+		hash, err := process(ctx, item)
+		response[idx] = &TodoItemWithHash{Item: item, Hash: hash}
+		return err
+	}
+
+	var list []func(context.Context) error
+	for idx, todo := range todos {
+		idx := idx
+		todo := todo
+		list = append(list, func(ctx context.Context) error {
+			return f(ctx, idx, toProtoTodoItem(todo))
+		})
+	}
+
+	err := parallel(ctx, list)
+
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+//transformPointer allocates the items up front and sets the hash through their pointers
+func transformPointer(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+
+	response := make([]*TodoItemWithHash, len(todos))
+
+	f := func(ctx context.Context, item *TodoItemWithHash) error {
+		hash, err := process(ctx, item.Item)
+		item.Hash = hash
+		return err
+	}
+
+	var list []func(context.Context) error
+	for idx, todo := range todos {
+		idx := idx
+		response[idx] = &TodoItemWithHash{Item: toProtoTodoItem(todo)}
+		list = append(list, func(ctx context.Context) error {
+			return f(ctx, response[idx])
+		})
+	}
+
+	err := parallel(ctx, list)
+
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+//transformAppend appends the items in completion order under a mutex
+func transformAppend(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+
+	mu := sync.Mutex{}
+	var response []*TodoItemWithHash
+
+	f := func(ctx context.Context, item *TodoItem) error {
+		hash, err := process(ctx, item)
+		mu.Lock()
+		response = append(response, &TodoItemWithHash{Item: item, Hash: hash})
+		mu.Unlock()
+		return err
+	}
+
+	var list []func(context.Context) error
+	for _, todo := range todos {
+		todo := todo
+		list = append(list, func(ctx context.Context) error {
+			return f(ctx, toProtoTodoItem(todo))
+		})
+	}
+
+	err := parallel(ctx, list)
+
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+//transformAppendPreAllocation is transformAppend with the capacity of the response allocated up front
+func transformAppendPreAllocation(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+
+	mu := sync.Mutex{}
+	response := make([]*TodoItemWithHash, 0, len(todos))
+
+	f := func(ctx context.Context, item *TodoItem) error {
+		hash, err := process(ctx, item)
+		mu.Lock()
+		response = append(response, &TodoItemWithHash{Item: item, Hash: hash})
+		mu.Unlock()
+		return err
+	}
+
+	var list []func(context.Context) error
+	for _, todo := range todos {
+		todo := todo
+		list = append(list, func(ctx context.Context) error {
+			return f(ctx, toProtoTodoItem(todo))
+		})
+	}
+
+	err := parallel(ctx, list)
+
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+//transformAppendChannels sends the items on a buffered channel and appends them once parallel returned
+func transformAppendChannels(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+
+	todoWithHashChannel := make(chan *TodoItemWithHash, len(todos))
+	var response []*TodoItemWithHash
+
+	f := func(ctx context.Context, item *TodoItem, ch chan *TodoItemWithHash) error {
+		hash, err := process(ctx, item)
+		ch <- &TodoItemWithHash{Item: item, Hash: hash}
+		return err
+	}
+
+	var list []func(context.Context) error
+	for _, todo := range todos {
+		todo := todo
+		list = append(list, func(ctx context.Context) error {
+			return f(ctx, toProtoTodoItem(todo), todoWithHashChannel)
+		})
+	}
+
+	err := parallel(ctx, list)
+
+	//parallel may not start every function, only read what was sent
+	close(todoWithHashChannel)
+	for item := range todoWithHashChannel {
+		response = append(response, item)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+//transformIndexingChannels sends the items on a buffered channel and stores them in a preallocated response
+func transformIndexingChannels(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+
+	length := len(todos)
+	todoWithHashChannel := make(chan *TodoItemWithHash, length)
+	response := make([]*TodoItemWithHash, length)
+
+	f := func(ctx context.Context, item *TodoItem, ch chan *TodoItemWithHash) error {
+		hash, err := process(ctx, item)
+		ch <- &TodoItemWithHash{Item: item, Hash: hash}
+		return err
+	}
+
+	var list []func(context.Context) error
+	for _, todo := range todos {
+		todo := todo
+		list = append(list, func(ctx context.Context) error {
+			return f(ctx, toProtoTodoItem(todo), todoWithHashChannel)
+		})
+	}
+
+	err := parallel(ctx, list)
+
+	//parallel may not start every function, only read what was sent
+	close(todoWithHashChannel)
+	i := 0
+	for item := range todoWithHashChannel {
+		response[i] = item
+		i++
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,179 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/models"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+//transformRecorder remembers the strategies it was told about
+type transformRecorder struct {
+	calls []string
+	items int
+	err   error
+}
+
+func (this *transformRecorder) Transform(strategy string, items int) func(error) {
+	this.calls = append(this.calls, strategy)
+	this.items += items
+	return func(err error) {
+		this.err = err
+	}
+}
+
+func TestTransformers(t *testing.T) {
+	todos := []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+		&models.TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"},
+	}
+	want := []*TodoItemWithHash{
+		{Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}, Hash: 1},
+		{Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}, Hash: 3},
+		{Item: &TodoItem{TodoID: 6, UserID: 1, Todo: "Task 3"}, Hash: 6},
+	}
+	process := func(ctx context.Context, item *TodoItem) (uint64, error) {
+		return uint64(item.TodoID), nil
+	}
+	sortTodos := cmpopts.SortSlices(func(x, y *TodoItemWithHash) bool {
+		return x.Item.TodoID < y.Item.TodoID
+	})
+
+	for _, name := range Transformers() {
+		recorder := &transformRecorder{}
+		server := Server{MaxWorkers: 2, Transforms: recorder}
+		transformer, err := server.transformer(name)
+		if err != nil {
+			t.Errorf("[%q]: transformer() got error %v", name, err)
+			continue
+		}
+
+		got, err := server.transform(context.Background(), transformer, todos, 0, process)
+
+		if err != nil {
+			t.Errorf("[%q]: transform() got error %v, want success", name, err)
+			continue
+		}
+		if diff := cmp.Diff(want, got, sortTodos, protocmp.Transform()); diff != "" {
+			t.Errorf("[%q]: transform() returned unexpected diff (-want, +got):\n%s", name, diff)
+		}
+		if diff := cmp.Diff([]string{name}, recorder.calls); diff != "" || recorder.items != len(todos) {
+			t.Errorf("[%q]: transform() reported %v with %d items, want one call with %d", name, recorder.calls, recorder.items, len(todos))
+		}
+	}
+}
+
+func TestTransformersCanceledWhileWaiting(t *testing.T) {
+	todos := []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+	}
+	process := func(ctx context.Context, item *TodoItem) (uint64, error) {
+		return uint64(item.TodoID), nil
+	}
+
+	for _, name := range Transformers() {
+		pool := NewPool(1)
+		pool.acquire(context.Background())
+		recorder := &transformRecorder{}
+		server := Server{Pool: pool, Transforms: recorder}
+		transformer, _ := server.transformer(name)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+
+		//the channel strategies used to wait for items of functions that never started
+		done := make(chan error, 1)
+		go func() {
+			_, err := server.transform(ctx, transformer, todos, 0, process)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != context.DeadlineExceeded || recorder.err != err {
+				t.Errorf("[%q]: transform() with a full pool got error %v and reported %v, want DeadlineExceeded", name, err, recorder.err)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("[%q]: transform() with a full pool didn't return", name)
+		}
+		cancel()
+		pool.release()
+	}
+}
+
+func TestRegisterTransformer(t *testing.T) {
+	//reversed hashes the items one after the other and returns them in reverse order
+	RegisterTransformer("reversed", TransformerFunc(func(ctx context.Context, todos []*models.TodoItem, parallel ParallelFunc, process ProcessFunc) ([]*TodoItemWithHash, error) {
+		var response []*TodoItemWithHash
+		for i := len(todos) - 1; i >= 0; i-- {
+			item := toProtoTodoItem(todos[i])
+			hash, err := process(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			response = append(response, &TodoItemWithHash{Item: item, Hash: hash})
+		}
+		return response, nil
+	}))
+	defer func() {
+		transformMu.Lock()
+		delete(transformers, "reversed")
+		transformMu.Unlock()
+	}()
+
+	fakeDS := testingDB{data: []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"},
+	}}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	resp, err := server.GetUserTodoItemsWithHash(context.Background(), &GetUserTodoItemsWithHashRequest{UserID: 1, Transform: "reversed"})
+	if err != nil {
+		t.Fatalf("GetUserTodoItemsWithHash() got error %v", err)
+	}
+	want := &GetUserTodoItemsWithHashResponse{
+		Items: []*TodoItemWithHash{
+			hashed(&TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}),
+			hashed(&TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"}),
+		},
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("GetUserTodoItemsWithHash() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterTransformer() of a taken name didn't panic")
+		}
+	}()
+	RegisterTransformer("indexing", nil)
+}
+
+func TestTransformer(t *testing.T) {
+	testData := []struct {
+		desc      string
+		server    string
+		request   string
+		wantName  string
+		wantError codes.Code
+	}{
+		{desc: "default", server: "", request: "", wantName: DefaultTransform, wantError: codes.OK},
+		{desc: "server default", server: "pointer", request: "", wantName: "pointer", wantError: codes.OK},
+		{desc: "request overrides server", server: "pointer", request: "append_channels", wantName: "append_channels", wantError: codes.OK},
+		{desc: "unknown", server: "", request: "sorted", wantName: "", wantError: codes.InvalidArgument},
+	}
+
+	for _, tc := range testData {
+		server := Server{Transform: tc.server}
+
+		transformer, err := server.transformer(tc.request)
+
+		if code := status.Code(err); code != tc.wantError || transformer.name != tc.wantName {
+			t.Errorf("[%q]: transformer() got %q and code %v, want %q and %v", tc.desc, transformer.name, code, tc.wantName, tc.wantError)
+		}
+	}
+}
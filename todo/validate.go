@@ -0,0 +1,156 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+//maxTodoLength is the longest todo text in characters
+const maxTodoLength = 1000
+
+//violations collects the invalid fields of a request
+type violations []*errdetails.BadRequest_FieldViolation
+
+func (this *violations) add(field string, format string, args ...interface{}) {
+	*this = append(*this, &errdetails.BadRequest_FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+//err returns an InvalidArgument error listing all violations, nil when there are none
+func (this violations) err() error {
+	if len(this) == 0 {
+		return nil
+	}
+	return badRequest(this)
+}
+
+func (this *violations) positive(field string, value int32) {
+	if value <= 0 {
+		this.add(field, "must be positive, got %d", value)
+	}
+}
+
+func (this *violations) text(field string, value string) {
+	switch {
+	case !utf8.ValidString(value):
+		this.add(field, "must be valid UTF-8")
+	case strings.TrimSpace(value) == "":
+		this.add(field, "must not be empty")
+	case utf8.RuneCountInString(value) > maxTodoLength:
+		this.add(field, "must be at most %d characters, got %d", maxTodoLength, utf8.RuneCountInString(value))
+	}
+}
+
+func (this *violations) priority(field string, value Priority) {
+	if _, ok := Priority_name[int32(value)]; !ok {
+		this.add(field, "unknown priority %d", value)
+	}
+}
+
+//Validate checks a new todo item, the todo id is assigned by the server
+func (this *AddTodoRequest) Validate() error {
+	var v violations
+	item := this.GetItem()
+	if item == nil {
+		v.add("item", "is required")
+		return v.err()
+	}
+	if item.TodoID != 0 {
+		v.add("item.todoID", "must not be set on create, got %d", item.TodoID)
+	}
+	v.positive("item.userID", item.UserID)
+	v.text("item.todo", item.Todo)
+	v.priority("item.priority", item.Priority)
+	return v.err()
+}
+
+//Validate checks the id and the text of the todo item, the other fields are not updated
+func (this *UpdateTodoRequest) Validate() error {
+	var v violations
+	item := this.GetItem()
+	if item == nil {
+		v.add("item", "is required")
+		return v.err()
+	}
+	v.positive("item.todoID", item.TodoID)
+	v.text("item.todo", item.Todo)
+	return v.err()
+}
+
+func (this *GetUserTodosRequest) Validate() error {
+	var v violations
+	v.positive("userID", this.GetUserID())
+	return v.err()
+}
+
+func (this *GetTodoRequest) Validate() error {
+	var v violations
+	v.positive("todoID", this.GetTodoID())
+	return v.err()
+}
+
+func (this *DeleteUserTodosRequest) Validate() error {
+	var v violations
+	v.positive("userID", this.GetUserID())
+	return v.err()
+}
+
+func (this *GetUserTodoItemsWithHashRequest) Validate() error {
+	var v violations
+	v.positive("userID", this.GetUserID())
+	if this.GetMaxWorkers() < 0 {
+		v.add("maxWorkers", "must not be negative, got %d", this.GetMaxWorkers())
+	}
+	v.hashAlgorithm("algorithm", this.GetAlgorithm())
+	v.transformer("transform", this.GetTransform())
+	return v.err()
+}
+
+func (this *StreamUserTodoItemsWithHashRequest) Validate() error {
+	var v violations
+	v.positive("userID", this.GetUserID())
+	if this.GetMaxWorkers() < 0 {
+		v.add("maxWorkers", "must not be negative, got %d", this.GetMaxWorkers())
+	}
+	v.hashAlgorithm("algorithm", this.GetAlgorithm())
+	return v.err()
+}
+
+func (this *DeleteTodoRequest) Validate() error {
+	var v violations
+	v.positive("todoID", this.GetTodoID())
+	return v.err()
+}
+
+func (this *CompleteTodoRequest) Validate() error {
+	var v violations
+	v.positive("todoID", this.GetTodoID())
+	return v.err()
+}
+
+func (this *ReopenTodoRequest) Validate() error {
+	var v violations
+	v.positive("todoID", this.GetTodoID())
+	return v.err()
+}
+
+func (this *SetTodoDueDateRequest) Validate() error {
+	var v violations
+	v.positive("todoID", this.GetTodoID())
+	return v.err()
+}
+
+func (this *SetTodoPriorityRequest) Validate() error {
+	var v violations
+	v.positive("todoID", this.GetTodoID())
+	v.priority("priority", this.GetPriority())
+	return v.err()
+}
+
+func (this *WatchTodosRequest) Validate() error {
+	var v violations
+	v.positive("userID", this.GetUserID())
+	return v.err()
+}
@@ -0,0 +1,140 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//violatedFields returns the fields listed in the BadRequest details of err
+func violatedFields(err error) []string {
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	testData := []struct {
+		desc       string
+		input      interface{ Validate() error }
+		wantFields []string
+	}{
+		{
+			desc:       "valid add",
+			input:      &AddTodoRequest{Item: &TodoItem{UserID: 1, Todo: "Task 1", Priority: Priority_PRIORITY_HIGH}},
+			wantFields: nil,
+		},
+		{
+			desc:       "add without item",
+			input:      &AddTodoRequest{},
+			wantFields: []string{"item"},
+		},
+		{
+			desc:       "add with todo id, negative user and empty text",
+			input:      &AddTodoRequest{Item: &TodoItem{TodoID: -1, UserID: -2, Todo: "  "}},
+			wantFields: []string{"item.todoID", "item.userID", "item.todo"},
+		},
+		{
+			desc:       "add with invalid UTF-8",
+			input:      &AddTodoRequest{Item: &TodoItem{UserID: 1, Todo: "Task \xff"}},
+			wantFields: []string{"item.todo"},
+		},
+		{
+			desc:       "add with long text",
+			input:      &AddTodoRequest{Item: &TodoItem{UserID: 1, Todo: strings.Repeat("ä", maxTodoLength+1)}},
+			wantFields: []string{"item.todo"},
+		},
+		{
+			desc:       "add with longest text",
+			input:      &AddTodoRequest{Item: &TodoItem{UserID: 1, Todo: strings.Repeat("ä", maxTodoLength)}},
+			wantFields: nil,
+		},
+		{
+			desc:       "add with unknown priority",
+			input:      &AddTodoRequest{Item: &TodoItem{UserID: 1, Todo: "Task 1", Priority: 9}},
+			wantFields: []string{"item.priority"},
+		},
+		{
+			desc:       "update without todo id",
+			input:      &UpdateTodoRequest{Item: &TodoItem{Todo: "Task 1"}},
+			wantFields: []string{"item.todoID"},
+		},
+		{
+			desc:       "get user todos of user 0",
+			input:      &GetUserTodosRequest{},
+			wantFields: []string{"userID"},
+		},
+		{
+			desc:       "delete user todos of negative user",
+			input:      &DeleteUserTodosRequest{UserID: -1},
+			wantFields: []string{"userID"},
+		},
+		{
+			desc:       "hash with negative max workers",
+			input:      &GetUserTodoItemsWithHashRequest{UserID: 1, MaxWorkers: -1},
+			wantFields: []string{"maxWorkers"},
+		},
+		{
+			desc:       "hash with unknown algorithm",
+			input:      &StreamUserTodoItemsWithHashRequest{UserID: 1, Algorithm: "md5"},
+			wantFields: []string{"algorithm"},
+		},
+		{
+			desc:       "hash with unknown transform",
+			input:      &GetUserTodoItemsWithHashRequest{UserID: 1, Transform: "sorted"},
+			wantFields: []string{"transform"},
+		},
+		{
+			desc:       "get todo 0",
+			input:      &GetTodoRequest{},
+			wantFields: []string{"todoID"},
+		},
+		{
+			desc:       "complete todo 0",
+			input:      &CompleteTodoRequest{},
+			wantFields: []string{"todoID"},
+		},
+		{
+			desc:       "set due date of todo 0",
+			input:      &SetTodoDueDateRequest{},
+			wantFields: []string{"todoID"},
+		},
+		{
+			desc:       "set unknown priority",
+			input:      &SetTodoPriorityRequest{TodoID: 1, Priority: 9},
+			wantFields: []string{"priority"},
+		},
+		{
+			desc:       "valid delete",
+			input:      &DeleteTodoRequest{TodoID: 3},
+			wantFields: nil,
+		},
+	}
+
+	for _, tc := range testData {
+		err := tc.input.Validate()
+
+		wantCode := codes.OK
+		if tc.wantFields != nil {
+			wantCode = codes.InvalidArgument
+		}
+		if code := status.Code(err); code != wantCode {
+			t.Errorf("[%q]: Validate() got code %v, want %v", tc.desc, code, wantCode)
+			continue
+		}
+
+		if diff := cmp.Diff(tc.wantFields, violatedFields(err)); diff != "" {
+			t.Errorf("[%q]: Validate() returned unexpected fields diff (-want, +got):\n%s", tc.desc, diff)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package todo
+
+import (
+	"context"
+	"sync"
+	"todo-app/logging"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//watchBufferSize is the number of events buffered for each WatchTodos stream
+const watchBufferSize = 64
+
+//errWatchOverflow ends the stream of a subscriber that fell behind, the client should
+//re-read its todos with GetUserTodos and watch again
+var errWatchOverflow = status.Error(codes.ResourceExhausted, "watcher fell behind, re-read todos and watch again")
+
+var errWatchClosed = status.Error(codes.Unavailable, "server is shutting down")
+
+//subscriber receives the events of one user
+type subscriber struct {
+	userID int32
+	events chan *TodoEvent
+	//done is closed with err set when the hub drops the subscriber
+	done chan struct{}
+	err  error
+}
+
+//hub fans out todo events to the subscribers of the item's user
+//publishing never blocks, a subscriber with a full buffer is dropped with errWatchOverflow
+//the zero value is ready to use
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[int32]map[*subscriber]struct{}
+	closed      bool
+}
+
+func (this *hub) subscribe(userID int32) *subscriber {
+	sub := &subscriber{userID: userID, events: make(chan *TodoEvent, watchBufferSize), done: make(chan struct{})}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.closed {
+		sub.err = errWatchClosed
+		close(sub.done)
+		return sub
+	}
+	if this.subscribers == nil {
+		this.subscribers = make(map[int32]map[*subscriber]struct{})
+	}
+	if this.subscribers[userID] == nil {
+		this.subscribers[userID] = make(map[*subscriber]struct{})
+	}
+	this.subscribers[userID][sub] = struct{}{}
+	return sub
+}
+
+func (this *hub) unsubscribe(sub *subscriber) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.remove(sub)
+}
+
+//remove deletes the subscriber, the lock must be held
+func (this *hub) remove(sub *subscriber) {
+	delete(this.subscribers[sub.userID], sub)
+	if len(this.subscribers[sub.userID]) == 0 {
+		delete(this.subscribers, sub.userID)
+	}
+}
+
+//drop ends the subscriber with err, the lock must be held
+func (this *hub) drop(sub *subscriber, err error) {
+	this.remove(sub)
+	sub.err = err
+	close(sub.done)
+}
+
+//active reports whether anybody watches, items are only read for events when it's true
+func (this *hub) active() bool {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return len(this.subscribers) > 0
+}
+
+//watching reports whether anybody watches the todos of the user
+func (this *hub) watching(userID int32) bool {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return len(this.subscribers[userID]) > 0
+}
+
+func (this *hub) publish(eventType EventType, item *TodoItem) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	event := &TodoEvent{Type: eventType, Item: item}
+	for sub := range this.subscribers[item.UserID] {
+		select {
+		case sub.events <- event:
+		default:
+			this.drop(sub, errWatchOverflow)
+		}
+	}
+}
+
+//close drops all subscribers so their streams end and new ones end at once
+func (this *hub) close() {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.closed = true
+	for _, subs := range this.subscribers {
+		for sub := range subs {
+			this.drop(sub, errWatchClosed)
+		}
+	}
+}
+
+//publishStored reads the written todo item and publishes it when anybody watches
+func (s *Server) publishStored(ctx context.Context, eventType EventType, todoID int32) (*TodoItem, error) {
+	stored, err := s.DS.GetTodoItem(ctx, todoID)
+	if err != nil {
+		return nil, err
+	}
+	item := toProtoTodoItem(stored)
+	if s.watchers.active() {
+		s.watchers.publish(eventType, item)
+	}
+	return item, nil
+}
+
+//StopWatching ends all WatchTodos streams, call it before stopping the gRPC server
+//as watch streams never end on their own
+func (s *Server) StopWatching() {
+	s.watchers.close()
+}
+
+//WatchTodos streams the created, updated and deleted todo items of the user until the client cancels
+func (s *Server) WatchTodos(message *WatchTodosRequest, stream TodoService_WatchTodosServer) error {
+	ctx := stream.Context()
+	logging.FromContext(ctx).Debug("received watch todos request", "request", message)
+	if err := s.authorizeUser(ctx, message.UserID); err != nil {
+		return err
+	}
+	sub := s.watchers.subscribe(message.UserID)
+	defer s.watchers.unsubscribe(sub)
+	for {
+		select {
+		case event := <-sub.events:
+			if err := stream.Send(event); err != nil {
+				return toStatusError(ctx, err)
+			}
+		case <-sub.done:
+			return sub.err
+		//client canceled or server stopped
+		case <-ctx.Done():
+			return toStatusError(ctx, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/models"
+
+	"github.com/google/go-cmp/cmp"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type testing_TodoService_WatchTodosServer struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *TodoEvent
+}
+
+func (this *testing_TodoService_WatchTodosServer) Send(event *TodoEvent) error {
+	this.events <- event
+	return nil
+}
+
+func (this *testing_TodoService_WatchTodosServer) Context() context.Context {
+	return this.ctx
+}
+
+//startWatching runs WatchTodos for the user until the returned cancel is called
+func startWatching(t *testing.T, server *Server, userID int32) (*testing_TodoService_WatchTodosServer, context.CancelFunc, chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &testing_TodoService_WatchTodosServer{ctx: ctx, events: make(chan *TodoEvent, 10)}
+	result := make(chan error, 1)
+	go func() {
+		result <- server.WatchTodos(&WatchTodosRequest{UserID: userID}, stream)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for !server.watchers.watching(userID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("WatchTodos() didn't subscribe user %d", userID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return stream, cancel, result
+}
+
+func receiveEvent(t *testing.T, stream *testing_TodoService_WatchTodosServer) *TodoEvent {
+	select {
+	case event := <-stream.events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatalf("no event received")
+		return nil
+	}
+}
+
+func TestWatchTodos(t *testing.T) {
+	fakeDS := testingDB{}
+	server := Server{DS: &fakeDS, WaitingTime: testingWaitingTime}
+	fakeDS.data = []*models.TodoItem{
+		&models.TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1"},
+		&models.TodoItem{TodoID: 2, UserID: 2, Todo: "Task 1"},
+	}
+	fakeDS.intResp = 3
+
+	stream, cancel, result := startWatching(t, &server, 1)
+	ctx := context.Background()
+
+	server.AddTodo(ctx, &AddTodoRequest{Item: &TodoItem{UserID: 2, Todo: "Task 2"}})
+	server.AddTodo(ctx, &AddTodoRequest{Item: &TodoItem{UserID: 1, Todo: "Task 2"}})
+	server.UpdateTodo(ctx, &UpdateTodoRequest{Item: &TodoItem{TodoID: 1, Todo: "Task 1 fixed"}})
+	server.CompleteTodo(ctx, &CompleteTodoRequest{TodoID: 1})
+	server.DeleteTodo(ctx, &DeleteTodoRequest{TodoID: 2})
+	server.DeleteTodo(ctx, &DeleteTodoRequest{TodoID: 1})
+
+	completedAt := toProtoTimestamp(testingTime)
+	want := []*TodoEvent{
+		&TodoEvent{Type: EventType_EVENT_TYPE_CREATED, Item: &TodoItem{TodoID: 3, UserID: 1, Todo: "Task 2"}},
+		&TodoEvent{Type: EventType_EVENT_TYPE_UPDATED, Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1 fixed"}},
+		&TodoEvent{Type: EventType_EVENT_TYPE_UPDATED, Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1 fixed", Completed: true, CompletedAt: completedAt}},
+		&TodoEvent{Type: EventType_EVENT_TYPE_DELETED, Item: &TodoItem{TodoID: 1, UserID: 1, Todo: "Task 1 fixed", Completed: true, CompletedAt: completedAt}},
+	}
+	var got []*TodoEvent
+	for range want {
+		got = append(got, receiveEvent(t, stream))
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("WatchTodos() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	cancel()
+	if err := <-result; status.Code(err) != codes.Canceled {
+		t.Errorf("WatchTodos() got error %v after cancel, want Canceled", err)
+	}
+	if server.watchers.active() {
+		t.Errorf("WatchTodos() didn't unsubscribe after cancel")
+	}
+}
+
+func TestWatchTodosSlowConsumer(t *testing.T) {
+	var watchers hub
+	slow := watchers.subscribe(1)
+	fast := watchers.subscribe(1)
+
+	for i := 0; i <= watchBufferSize; i++ {
+		watchers.publish(EventType_EVENT_TYPE_CREATED, &TodoItem{TodoID: int32(i + 1), UserID: 1})
+		if i < watchBufferSize {
+			<-fast.events
+		}
+	}
+
+	select {
+	case <-slow.done:
+		if slow.err != errWatchOverflow {
+			t.Errorf("slow subscriber got error %v, want %v", slow.err, errWatchOverflow)
+		}
+	default:
+		t.Errorf("slow subscriber wasn't dropped with a full buffer")
+	}
+	select {
+	case <-fast.done:
+		t.Errorf("fast subscriber was dropped with error %v", fast.err)
+	default:
+	}
+}
+
+func TestStopWatching(t *testing.T) {
+	server := Server{DS: &testingDB{}, WaitingTime: testingWaitingTime}
+	_, cancel, result := startWatching(t, &server, 1)
+	defer cancel()
+
+	server.StopWatching()
+
+	if err := <-result; status.Code(err) != codes.Unavailable {
+		t.Errorf("WatchTodos() got error %v after StopWatching(), want Unavailable", err)
+	}
+	stream := &testing_TodoService_WatchTodosServer{ctx: context.Background()}
+	if err := server.WatchTodos(&WatchTodosRequest{UserID: 1}, stream); status.Code(err) != codes.Unavailable {
+		t.Errorf("WatchTodos() after StopWatching() got error %v, want Unavailable", err)
+	}
+}
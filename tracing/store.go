@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/models"
+	"todo-app/todo"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//store decorates a DataStore and starts a span for every call
+type store struct {
+	ds     todo.DataStore
+	tracer trace.Tracer
+	system attribute.KeyValue
+}
+
+//Store wraps the data store so every method call is traced, system names the backend, e.g. mysql
+func Store(ds todo.DataStore, system string) todo.DataStore {
+	return &store{ds: ds, tracer: otel.Tracer("todo-app/tracing"), system: attribute.String("db.system", system)}
+}
+
+//start returns the span context of a call and the function ending the span with its result
+func (this *store) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	ctx, span := this.tracer.Start(ctx, "DataStore."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, this.system)...))
+	return ctx, func(err error) {
+		//a missing item is an answer, not a failure of the data store
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (this *store) InsertTodoItem(ctx context.Context, item *models.TodoItem) (int32, error) {
+	ctx, end := this.start(ctx, "InsertTodoItem", attribute.Int("todo.user_id", int(item.UserID)))
+	id, err := this.ds.InsertTodoItem(ctx, item)
+	end(err)
+	return id, err
+}
+
+func (this *store) GetAllTodos(ctx context.Context) ([]*models.TodoItem, error) {
+	ctx, end := this.start(ctx, "GetAllTodos")
+	items, err := this.ds.GetAllTodos(ctx)
+	end(err)
+	return items, err
+}
+
+func (this *store) GetUserTodos(ctx context.Context, userID int32) ([]*models.TodoItem, error) {
+	ctx, end := this.start(ctx, "GetUserTodos", attribute.Int("todo.user_id", int(userID)))
+	items, err := this.ds.GetUserTodos(ctx, userID)
+	end(err)
+	return items, err
+}
+
+func (this *store) GetAllTodosPage(ctx context.Context, afterID int32, limit int) ([]*models.TodoItem, error) {
+	ctx, end := this.start(ctx, "GetAllTodosPage", attribute.Int("todo.after_id", int(afterID)), attribute.Int("todo.limit", limit))
+	items, err := this.ds.GetAllTodosPage(ctx, afterID, limit)
+	end(err)
+	return items, err
+}
+
+func (this *store) GetUserTodosPage(ctx context.Context, userID int32, afterID int32, limit int) ([]*models.TodoItem, error) {
+	ctx, end := this.start(ctx, "GetUserTodosPage", attribute.Int("todo.user_id", int(userID)), attribute.Int("todo.after_id", int(afterID)), attribute.Int("todo.limit", limit))
+	items, err := this.ds.GetUserTodosPage(ctx, userID, afterID, limit)
+	end(err)
+	return items, err
+}
+
+func (this *store) DeleteUserTodos(ctx context.Context, userID int32) error {
+	ctx, end := this.start(ctx, "DeleteUserTodos", attribute.Int("todo.user_id", int(userID)))
+	err := this.ds.DeleteUserTodos(ctx, userID)
+	end(err)
+	return err
+}
+
+func (this *store) GetTodoItem(ctx context.Context, todoID int32) (*models.TodoItem, error) {
+	ctx, end := this.start(ctx, "GetTodoItem", attribute.Int("todo.id", int(todoID)))
+	item, err := this.ds.GetTodoItem(ctx, todoID)
+	end(err)
+	return item, err
+}
+
+func (this *store) UpdateTodoItem(ctx context.Context, item *models.TodoItem) error {
+	ctx, end := this.start(ctx, "UpdateTodoItem", attribute.Int("todo.id", int(item.TodoID)))
+	err := this.ds.UpdateTodoItem(ctx, item)
+	end(err)
+	return err
+}
+
+func (this *store) SetTodoCompleted(ctx context.Context, todoID int32, completed bool) error {
+	ctx, end := this.start(ctx, "SetTodoCompleted", attribute.Int("todo.id", int(todoID)))
+	err := this.ds.SetTodoCompleted(ctx, todoID, completed)
+	end(err)
+	return err
+}
+
+func (this *store) SetTodoDueDate(ctx context.Context, todoID int32, dueDate *time.Time) error {
+	ctx, end := this.start(ctx, "SetTodoDueDate", attribute.Int("todo.id", int(todoID)))
+	err := this.ds.SetTodoDueDate(ctx, todoID, dueDate)
+	end(err)
+	return err
+}
+
+func (this *store) SetTodoPriority(ctx context.Context, todoID int32, priority models.Priority) error {
+	ctx, end := this.start(ctx, "SetTodoPriority", attribute.Int("todo.id", int(todoID)))
+	err := this.ds.SetTodoPriority(ctx, todoID, priority)
+	end(err)
+	return err
+}
+
+func (this *store) DeleteTodoItem(ctx context.Context, todoID int32) error {
+	ctx, end := this.start(ctx, "DeleteTodoItem", attribute.Int("todo.id", int(todoID)))
+	err := this.ds.DeleteTodoItem(ctx, todoID)
+	end(err)
+	return err
+}
+
+func (this *store) Truncate(ctx context.Context) error {
+	ctx, end := this.start(ctx, "Truncate")
+	err := this.ds.Truncate(ctx)
+	end(err)
+	return err
+}
+
+func (this *store) GetUserTodosVersion(ctx context.Context, userID int32) (int64, error) {
+	ctx, end := this.start(ctx, "GetUserTodosVersion", attribute.Int("todo.user_id", int(userID)))
+	version, err := this.ds.GetUserTodosVersion(ctx, userID)
+	end(err)
+	return version, err
+}
+
+//Ping is not traced, the health checker calls it every few seconds without a request behind it
+func (this *store) Ping(ctx context.Context) error {
+	return this.ds.Ping(ctx)
+}
+
+func (this *store) Close() error {
+	return this.ds.Close()
+}
@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/models"
+	"todo-app/store/memstore"
+	"todo-app/todo"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+//spans records the spans of all tests, the provider is installed once because tracers
+//created before the first provider keep delegating to it
+var spans = tracetest.NewInMemoryExporter()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)))
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	testData := []struct {
+		desc     string
+		exporter string
+		wantErr  bool
+	}{
+		{desc: "none", exporter: "none", wantErr: false},
+		{desc: "stdout", exporter: "stdout", wantErr: false},
+		{desc: "unknown", exporter: "jaeger", wantErr: true},
+	}
+
+	for _, tc := range testData {
+		shutdown, err := Setup(context.Background(), "test", tc.exporter, "")
+
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("[%q]: Setup() got success, want an error", tc.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%q]: Setup() got error %v, want success", tc.desc, err)
+			continue
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("[%q]: shutdown() got error %v", tc.desc, err)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	spans.Reset()
+	ds := Store(memstore.New(), "memory")
+	ctx := context.Background()
+
+	id, _ := ds.InsertTodoItem(ctx, &models.TodoItem{UserID: 1, Todo: "trace"})
+	ds.GetTodoItem(ctx, id+1)
+	ds.Ping(ctx)
+
+	testData := []struct {
+		name       string
+		wantStatus codes.Code
+	}{
+		{name: "DataStore.InsertTodoItem", wantStatus: codes.Unset},
+		{name: "DataStore.GetTodoItem", wantStatus: codes.Unset},
+	}
+
+	got := spans.GetSpans()
+	if len(got) != len(testData) {
+		t.Fatalf("Store() recorded %d spans, want %d", len(got), len(testData))
+	}
+	for i, tc := range testData {
+		if got[i].Name != tc.name || got[i].Status.Code != tc.wantStatus {
+			t.Errorf("Store() span %d got %s with status %v, want %s with status %v", i, got[i].Name, got[i].Status.Code, tc.name, tc.wantStatus)
+		}
+	}
+	for _, span := range got {
+		if span.Name == "DataStore.Ping" {
+			t.Errorf("Store() traced the health check ping")
+		}
+	}
+}
+
+func TestHashFanOutSpans(t *testing.T) {
+	spans.Reset()
+	ds := Store(memstore.New(), "memory")
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		ds.InsertTodoItem(ctx, &models.TodoItem{UserID: 7, Todo: "hash"})
+	}
+	s := todo.Server{DS: ds, WaitingTime: 2 * time.Millisecond}
+	spans.Reset()
+
+	ctx, root := otel.Tracer("test").Start(ctx, "request")
+	_, err := s.GetUserTodoItemsWithHash(ctx, &todo.GetUserTodoItemsWithHashRequest{UserID: 7})
+	root.End()
+	if err != nil {
+		t.Fatalf("GetUserTodoItemsWithHash() got error %v", err)
+	}
+
+	byName := map[string][]tracetest.SpanStub{}
+	for _, span := range spans.GetSpans() {
+		if span.SpanContext.TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("span %s is not part of the request trace", span.Name)
+		}
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+	if len(byName["DataStore.GetUserTodos"]) != 1 || len(byName["parallel"]) != 1 {
+		t.Fatalf("GetUserTodoItemsWithHash() got spans %v, want one DataStore.GetUserTodos and one parallel", byName)
+	}
+	parallel := byName["parallel"][0]
+	if got := len(byName["computeTodoHash"]); got != 3 {
+		t.Errorf("GetUserTodoItemsWithHash() got %d computeTodoHash spans, want 3", got)
+	}
+	for _, span := range byName["computeTodoHash"] {
+		if span.Parent.SpanID() != parallel.SpanContext.SpanID() {
+			t.Errorf("computeTodoHash span is not a child of the parallel span")
+		}
+	}
+}
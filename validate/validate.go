@@ -0,0 +1,59 @@
+//Package validate rejects requests whose Validate method fails before they reach the handlers
+package validate
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//Validator is implemented by request messages with validation rules
+type Validator interface {
+	//Validate returns an InvalidArgument status error listing the invalid fields
+	Validate() error
+}
+
+//check validates the message when it has rules, errors without a status are reported as InvalidArgument
+func check(message interface{}) error {
+	validator, ok := message.(Validator)
+	if !ok {
+		return nil
+	}
+	err := validator.Validate()
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := check(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+//StreamServerInterceptor validates every message received from the client
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: stream})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (this *validatingStream) RecvMsg(message interface{}) error {
+	if err := this.ServerStream.RecvMsg(message); err != nil {
+		return err
+	}
+	return check(message)
+}
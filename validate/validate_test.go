@@ -0,0 +1,77 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type request struct {
+	err error
+}
+
+func (this *request) Validate() error {
+	return this.err
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	testData := []struct {
+		desc      string
+		input     interface{}
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{desc: "valid", input: &request{}, wantCode: codes.OK, wantCalls: 1},
+		{desc: "status error", input: &request{err: status.Error(codes.InvalidArgument, "userID: must be positive")}, wantCode: codes.InvalidArgument, wantCalls: 0},
+		{desc: "plain error", input: &request{err: errors.New("bad")}, wantCode: codes.InvalidArgument, wantCalls: 0},
+		{desc: "no rules", input: "message", wantCode: codes.OK, wantCalls: 1},
+	}
+
+	interceptor := UnaryServerInterceptor()
+	for _, tc := range testData {
+		calls := 0
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return nil, nil
+		}
+
+		_, err := interceptor(context.Background(), tc.input, &grpc.UnaryServerInfo{}, handler)
+
+		if code := status.Code(err); code != tc.wantCode || calls != tc.wantCalls {
+			t.Errorf("[%q]: interceptor got code %v and %d calls, want %v and %d calls", tc.desc, code, calls, tc.wantCode, tc.wantCalls)
+		}
+	}
+}
+
+//recvStream receives the messages in order
+type recvStream struct {
+	grpc.ServerStream
+	messages []*request
+}
+
+func (this *recvStream) RecvMsg(message interface{}) error {
+	*message.(*request) = *this.messages[0]
+	this.messages = this.messages[1:]
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	stream := &recvStream{messages: []*request{{}, {err: errors.New("bad")}}}
+	var errs []error
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		for i := 0; i < 2; i++ {
+			errs = append(errs, stream.RecvMsg(&request{}))
+		}
+		return nil
+	}
+
+	StreamServerInterceptor()(nil, stream, &grpc.StreamServerInfo{}, handler)
+
+	if status.Code(errs[0]) != codes.OK || status.Code(errs[1]) != codes.InvalidArgument {
+		t.Errorf("RecvMsg() got errors %v, want success then InvalidArgument", errs)
+	}
+}